@@ -0,0 +1,396 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/jeremiev/lazyrss/internal/xdg"
+)
+
+// Config holds the user-editable settings from config.toml. Toggles that are
+// flipped with a key (article view, entry dates) stay in the settings table.
+type Config struct {
+	// Theme is "auto", a built-in theme, a theme from Themes, a glamour style
+	// name or the path to a glamour style file
+	Theme   string           `toml:"theme"`
+	Themes  map[string]Theme `toml:"themes"`
+	Refresh Refresh          `toml:"refresh"`
+	Layout  Layout           `toml:"layout"`
+	Browser Browser          `toml:"browser"`
+	Dates   Dates            `toml:"dates"`
+	Article Article          `toml:"article"`
+	HTTP    HTTP             `toml:"http"`
+	Trash   Trash            `toml:"trash"`
+	// Handlers open the links they match instead of the browser, the first
+	// match wins
+	Handlers []Handler `toml:"handlers"`
+	// Pipes are commands articles can be sent to by name
+	Pipes []Pipe `toml:"pipes"`
+	// Keys maps action names to the keys that trigger them
+	Keys map[string][]string `toml:"keys"`
+}
+
+// Theme is a user-defined color scheme. Unset colors come from Base.
+type Theme struct {
+	Base    string `toml:"base"`
+	Accent  string `toml:"accent"`
+	Text    string `toml:"text"`
+	Muted   string `toml:"muted"`
+	Border  string `toml:"border"`
+	Error   string `toml:"error"`
+	Link    string `toml:"link"`
+	TitleFg string `toml:"title_fg"`
+	TitleBg string `toml:"title_bg"`
+	// Glamour is the article style, a glamour style name or a style file
+	Glamour string `toml:"glamour"`
+}
+
+type Refresh struct {
+	// Interval between background syncs of all feeds, 0 disables them
+	Interval  time.Duration `toml:"interval"`
+	OnStartup bool          `toml:"on_startup"`
+}
+
+type Layout struct {
+	// Mode is one of LayoutModes
+	Mode         string  `toml:"mode"`
+	FeedsRatio   float64 `toml:"feeds_ratio"`
+	EntriesRatio float64 `toml:"entries_ratio"`
+}
+
+// Layouts of the panes: side by side, the article below the articles list,
+// or one pane at a time. Auto picks one by the size of the window.
+const (
+	LayoutAuto    = "auto"
+	LayoutColumns = "columns"
+	LayoutStacked = "stacked"
+	LayoutSingle  = "single"
+)
+
+var LayoutModes = []string{LayoutAuto, LayoutColumns, LayoutStacked, LayoutSingle}
+
+type Browser struct {
+	// Command used to open links, {url} is replaced by the link. When empty
+	// $BROWSER and then the OS default opener are used.
+	Command string `toml:"command"`
+}
+
+// Handler opens links matching a pattern with its own command, such as a
+// video player for videos
+type Handler struct {
+	// Match is a regular expression tried on the whole link
+	Match string `toml:"match"`
+	// Command is run like the browser command, {url} is replaced by the link.
+	// Quotes group words into one argument.
+	Command string `toml:"command"`
+	// Terminal programs take over the screen until they exit, others run in
+	// the background
+	Terminal bool `toml:"terminal"`
+}
+
+// Matches reports whether the handler opens url
+func (h Handler) Matches(url string) bool {
+	ok, _ := regexp.MatchString(h.Match, url)
+	return ok
+}
+
+// Pipe is a command the article is sent to on its standard input
+type Pipe struct {
+	Name string `toml:"name"`
+	// Command is run by the shell
+	Command string `toml:"command"`
+	// Format is one of PipeFormats, Markdown when empty
+	Format string `toml:"format"`
+}
+
+// What of the article is piped to a command
+const (
+	PipeJSON     = "json"
+	PipeMarkdown = "markdown"
+	PipeHTML     = "html"
+	PipeURL      = "url"
+)
+
+var PipeFormats = []string{PipeJSON, PipeMarkdown, PipeHTML, PipeURL}
+
+type Dates struct {
+	// Format of the date column in the articles pane
+	ListFormat string `toml:"list_format"`
+	// Format of the date above an article
+	ArticleFormat string `toml:"article_format"`
+	// Relative shows the dates of the last week as "3h ago" or "yesterday"
+	Relative bool `toml:"relative"`
+	// TimeZone dates are shown in, an IANA name such as "Europe/Paris".
+	// Empty uses the local time zone.
+	TimeZone string `toml:"time_zone"`
+	// GroupByDay lists articles sorted by date under day headers
+	GroupByDay bool `toml:"group_by_day"`
+}
+
+// Location is the time zone dates are shown in
+func (d Dates) Location() *time.Location {
+	if d.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(d.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+type Article struct {
+	// Footnotes numbers the links of articles and lists them at the end
+	Footnotes bool `toml:"footnotes"`
+	// MaxWidth is the longest line of the fullscreen reader, in columns
+	MaxWidth int `toml:"max_width"`
+	// Images is one of ImageProtocols
+	Images string `toml:"images"`
+}
+
+// Terminal graphics protocols images can be shown with. Auto picks one by
+// the terminal, off shows images as links.
+const (
+	ImagesAuto   = "auto"
+	ImagesKitty  = "kitty"
+	ImagesITerm2 = "iterm2"
+	ImagesSixel  = "sixel"
+	ImagesOff    = "off"
+)
+
+var ImageProtocols = []string{ImagesAuto, ImagesKitty, ImagesITerm2, ImagesSixel, ImagesOff}
+
+type HTTP struct {
+	Timeout   time.Duration `toml:"timeout"`
+	UserAgent string        `toml:"user_agent"`
+	Proxy     string        `toml:"proxy"`
+}
+
+type Trash struct {
+	// Keep is how long deleted feeds can be restored before they are
+	// removed for good, at the next start
+	Keep time.Duration `toml:"keep"`
+}
+
+func Default() *Config {
+	return &Config{
+		Theme: "auto",
+		Refresh: Refresh{
+			Interval:  30 * time.Minute,
+			OnStartup: true,
+		},
+		Layout: Layout{
+			Mode:         LayoutAuto,
+			FeedsRatio:   0.2,
+			EntriesRatio: 0.25,
+		},
+		Dates: Dates{
+			ArticleFormat: "Mon, 02 Jan 2006 15:04",
+		},
+		Article: Article{
+			MaxWidth: 80,
+			Images:   ImagesAuto,
+		},
+		HTTP: HTTP{
+			Timeout:   10 * time.Second,
+			UserAgent: "lazyrss",
+		},
+		Trash: Trash{
+			Keep: 30 * 24 * time.Hour,
+		},
+	}
+}
+
+// BuiltinThemes are the themes that come with their own color scheme
+var BuiltinThemes = []string{"dark", "light", "high-contrast"}
+
+func isBuiltinTheme(name string) bool {
+	for _, t := range BuiltinThemes {
+		if t == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsGlamourStyle reports whether name is a glamour style name or file
+func IsGlamourStyle(name string) bool {
+	if _, ok := styles.DefaultStyles[name]; ok {
+		return true
+	}
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+func isColor(c string) bool {
+	if strings.HasPrefix(c, "#") {
+		_, err := strconv.ParseUint(c[1:], 16, 32)
+		return err == nil && (len(c) == 4 || len(c) == 7)
+	}
+	n, err := strconv.Atoi(c)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// Path returns the location of config.toml in the config dir.
+func Path() (string, error) {
+	dir, err := xdg.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Load reads the config file at path on top of the defaults. A missing file
+// is not an error. Every problem found is reported, not just the first one.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	md, err := toml.DecodeFile(path, cfg)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return nil, fmt.Errorf("%s:%d: %s", path, perr.Position.Line, perr.Message)
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	cfg.expandPaths()
+
+	var problems []string
+	for _, k := range md.Undecoded() {
+		problems = append(problems, fmt.Sprintf("unknown setting %q", k.String()))
+	}
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%s:\n  %s", path, strings.Join(problems, "\n  "))
+	}
+	return cfg, nil
+}
+
+// expandPaths resolves a leading ~ in settings that name files
+func (c *Config) expandPaths() {
+	c.Theme = ExpandHome(c.Theme)
+	for name, t := range c.Themes {
+		t.Glamour = ExpandHome(t.Glamour)
+		c.Themes[name] = t
+	}
+}
+
+// ExpandHome replaces a leading ~ with the home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func (c *Config) validate() []string {
+	var problems []string
+	if c.Refresh.Interval < 0 {
+		problems = append(problems, "refresh.interval must not be negative")
+	} else if c.Refresh.Interval > 0 && c.Refresh.Interval < time.Minute {
+		problems = append(problems, "refresh.interval must be at least 1m (or 0 to disable)")
+	}
+	if !slices.Contains(LayoutModes, c.Layout.Mode) {
+		problems = append(problems, fmt.Sprintf("layout.mode must be one of %s", strings.Join(LayoutModes, ", ")))
+	}
+	if c.Layout.FeedsRatio <= 0 || c.Layout.FeedsRatio >= 1 {
+		problems = append(problems, "layout.feeds_ratio must be between 0 and 1")
+	}
+	if c.Layout.EntriesRatio <= 0 || c.Layout.EntriesRatio >= 1 {
+		problems = append(problems, "layout.entries_ratio must be between 0 and 1")
+	}
+	if c.Layout.FeedsRatio+c.Layout.EntriesRatio >= 0.9 {
+		problems = append(problems, "layout.feeds_ratio + layout.entries_ratio must leave room for the article")
+	}
+	if c.Dates.TimeZone != "" {
+		if _, err := time.LoadLocation(c.Dates.TimeZone); err != nil {
+			problems = append(problems, fmt.Sprintf("dates.time_zone %q is not a time zone name", c.Dates.TimeZone))
+		}
+	}
+	if c.Article.MaxWidth < 20 {
+		problems = append(problems, "article.max_width must be at least 20")
+	}
+	if !slices.Contains(ImageProtocols, c.Article.Images) {
+		problems = append(problems, fmt.Sprintf("article.images must be one of %s", strings.Join(ImageProtocols, ", ")))
+	}
+	if _, ok := c.Themes[c.Theme]; !ok && c.Theme != "auto" && !isBuiltinTheme(c.Theme) && !IsGlamourStyle(c.Theme) {
+		problems = append(problems, fmt.Sprintf("theme %q is not auto, one of %s, a [themes] entry or a glamour style", c.Theme, strings.Join(BuiltinThemes, ", ")))
+	}
+	names := make([]string, 0, len(c.Themes))
+	for name := range c.Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		t := c.Themes[name]
+		if t.Base != "" && !isBuiltinTheme(t.Base) {
+			problems = append(problems, fmt.Sprintf("themes.%s.base must be one of %s", name, strings.Join(BuiltinThemes, ", ")))
+		}
+		if t.Glamour != "" && !IsGlamourStyle(t.Glamour) {
+			problems = append(problems, fmt.Sprintf("themes.%s.glamour %q is not a glamour style name or file", name, t.Glamour))
+		}
+		colors := []struct{ key, value string }{
+			{"accent", t.Accent}, {"text", t.Text}, {"muted", t.Muted}, {"border", t.Border},
+			{"error", t.Error}, {"link", t.Link}, {"title_fg", t.TitleFg}, {"title_bg", t.TitleBg},
+		}
+		for _, col := range colors {
+			if col.value != "" && !isColor(col.value) {
+				problems = append(problems, fmt.Sprintf("themes.%s.%s %q must be a #rrggbb color or an ANSI number", name, col.key, col.value))
+			}
+		}
+	}
+	for i, h := range c.Handlers {
+		if _, err := regexp.Compile(h.Match); err != nil || h.Match == "" {
+			problems = append(problems, fmt.Sprintf("handlers[%d].match %q is not a regular expression", i, h.Match))
+		}
+		if strings.TrimSpace(h.Command) == "" {
+			problems = append(problems, fmt.Sprintf("handlers[%d].command must not be empty", i))
+		}
+	}
+	pipes := make(map[string]bool)
+	for i, p := range c.Pipes {
+		switch {
+		case p.Name == "" || strings.ContainsAny(p.Name, " \t"):
+			problems = append(problems, fmt.Sprintf("pipes[%d].name must be a single word", i))
+		case pipes[p.Name]:
+			problems = append(problems, fmt.Sprintf("pipes[%d].name %q is used twice", i, p.Name))
+		}
+		pipes[p.Name] = true
+		if strings.TrimSpace(p.Command) == "" {
+			problems = append(problems, fmt.Sprintf("pipes[%d].command must not be empty", i))
+		}
+		if p.Format != "" && !slices.Contains(PipeFormats, p.Format) {
+			problems = append(problems, fmt.Sprintf("pipes[%d].format must be one of %s", i, strings.Join(PipeFormats, ", ")))
+		}
+	}
+	if c.HTTP.Timeout <= 0 {
+		problems = append(problems, "http.timeout must be positive")
+	}
+	if c.HTTP.Proxy != "" {
+		if u, err := url.Parse(c.HTTP.Proxy); err != nil || u.Scheme == "" || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("http.proxy %q is not a valid URL", c.HTTP.Proxy))
+		}
+	}
+	if c.Trash.Keep < 0 {
+		problems = append(problems, "trash.keep must not be negative")
+	}
+	return problems
+}
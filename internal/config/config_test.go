@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name     string
+		toml     string // no file when empty
+		problems []string
+		check    func(*Config) bool
+	}{
+		{"missing file", "", nil, func(c *Config) bool { return c.Refresh.Interval == 30*time.Minute }},
+		{"overrides", "theme = \"light\"\n[refresh]\ninterval = \"1h\"\n[layout]\nfeeds_ratio = 0.3\n", nil,
+			func(c *Config) bool {
+				return c.Theme == "light" && c.Refresh.Interval == time.Hour && c.Layout.FeedsRatio == 0.3 &&
+					c.Layout.EntriesRatio == 0.25
+			}},
+		{"syntax error", "[refresh]\ninterval = 30m\n", []string{"config.toml:2:"}, nil},
+		{"unknown setting", "colour = \"red\"\n", []string{`unknown setting "colour"`}, nil},
+		{"every problem", "[refresh]\ninterval = \"10s\"\n[layout]\nmode = \"diagonal\"\n",
+			[]string{"refresh.interval must be at least 1m", "layout.mode must be one of"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if tt.toml != "" {
+				if err := os.WriteFile(path, []byte(tt.toml), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg, err := Load(path)
+			if len(tt.problems) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !tt.check(cfg) {
+					t.Errorf("unexpected config %+v", cfg)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("no error, want %q", tt.problems)
+			}
+			for _, p := range tt.problems {
+				if !strings.Contains(err.Error(), p) {
+					t.Errorf("error %q doesn't mention %q", err, p)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*Config)
+		problem string // empty when valid
+	}{
+		{"defaults", func(*Config) {}, ""},
+		{"refresh disabled", func(c *Config) { c.Refresh.Interval = 0 }, ""},
+		{"negative refresh", func(c *Config) { c.Refresh.Interval = -time.Minute }, "refresh.interval must not be negative"},
+		{"ratio out of range", func(c *Config) { c.Layout.FeedsRatio = 1 }, "layout.feeds_ratio must be between 0 and 1"},
+		{"no room for the article", func(c *Config) { c.Layout.FeedsRatio, c.Layout.EntriesRatio = 0.5, 0.45 }, "must leave room for the article"},
+		{"time zone", func(c *Config) { c.Dates.TimeZone = "Mars/Olympus" }, `dates.time_zone "Mars/Olympus"`},
+		{"narrow reader", func(c *Config) { c.Article.MaxWidth = 10 }, "article.max_width must be at least 20"},
+		{"unknown theme", func(c *Config) { c.Theme = "sepia" }, `theme "sepia"`},
+		{"custom theme", func(c *Config) {
+			c.Theme = "mine"
+			c.Themes = map[string]Theme{"mine": {Base: "light", Accent: "#d33682", Text: "245"}}
+		}, ""},
+		{"bad color", func(c *Config) { c.Themes = map[string]Theme{"mine": {Accent: "pink"}} }, `themes.mine.accent "pink"`},
+		{"bad handler", func(c *Config) { c.Handlers = []Handler{{Match: "(", Command: "mpv"}} }, "handlers[0].match"},
+		{"pipe used twice", func(c *Config) { c.Pipes = []Pipe{{Name: "say", Command: "espeak"}, {Name: "say", Command: "say"}} }, `pipes[1].name "say" is used twice`},
+		{"pipe format", func(c *Config) { c.Pipes = []Pipe{{Name: "say", Command: "espeak", Format: "pdf"}} }, "pipes[0].format"},
+		{"zero timeout", func(c *Config) { c.HTTP.Timeout = 0 }, "http.timeout must be positive"},
+		{"bad proxy", func(c *Config) { c.HTTP.Proxy = "localhost" }, `http.proxy "localhost"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.change(c)
+			problems := strings.Join(c.validate(), "\n")
+			switch {
+			case tt.problem == "" && problems != "":
+				t.Errorf("unexpected problems: %s", problems)
+			case tt.problem != "" && !strings.Contains(problems, tt.problem):
+				t.Errorf("problems %q don't mention %q", problems, tt.problem)
+			}
+		})
+	}
+}
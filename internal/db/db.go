@@ -2,10 +2,14 @@ package db
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/jeremiev/lazyrss/internal/xdg"
+
 	_ "modernc.org/sqlite"
 )
 
@@ -18,6 +22,9 @@ type Feed struct {
 	LastReadAt  time.Time
 	UnreadCount int
 	Position    int
+	Folder      string
+	// Paused feeds are left out of syncing
+	Paused bool
 }
 
 type Entry struct {
@@ -29,22 +36,192 @@ type Entry struct {
 	Content     string
 	PublishedAt time.Time
 	Read        bool
+	Starred     bool
+	// FeedTitle is only filled by queries spanning several feeds
+	FeedTitle string
+}
+
+// FeedSort is an order of the feeds pane
+type FeedSort string
+
+const (
+	FeedSortManual  FeedSort = "manual"
+	FeedSortTitle   FeedSort = "title"
+	FeedSortUnread  FeedSort = "unread"
+	FeedSortUpdated FeedSort = "updated"
+)
+
+// FeedSorts lists the feed orders in the order they are cycled through
+var FeedSorts = []FeedSort{FeedSortManual, FeedSortTitle, FeedSortUnread, FeedSortUpdated}
+
+var feedOrders = map[FeedSort]string{
+	FeedSortManual:  "f.position ASC, f.title ASC",
+	FeedSortTitle:   "f.title COLLATE NOCASE ASC",
+	FeedSortUnread:  "unread_count DESC, f.position ASC",
+	FeedSortUpdated: "(SELECT MAX(e.published_at) FROM entries e WHERE e.feed_id = f.id) DESC, f.position ASC",
+}
+
+// EntrySort is an order of the entries of a feed
+type EntrySort string
+
+const (
+	EntrySortNewest EntrySort = "newest"
+	EntrySortOldest EntrySort = "oldest"
+	EntrySortTitle  EntrySort = "title"
+	EntrySortUnread EntrySort = "unread"
+)
+
+// EntrySorts lists the entry orders in the order they are cycled through
+var EntrySorts = []EntrySort{EntrySortNewest, EntrySortOldest, EntrySortTitle, EntrySortUnread}
+
+var entryOrders = map[EntrySort]string{
+	EntrySortNewest: "e.published_at DESC, e.id DESC",
+	EntrySortOldest: "e.published_at ASC, e.id ASC",
+	EntrySortTitle:  "e.title COLLATE NOCASE ASC, e.published_at DESC",
+	EntrySortUnread: "e.read ASC, e.published_at DESC, e.id DESC",
+}
+
+// DateRange limits the entries of a feed to a publication period
+type DateRange string
+
+const (
+	DateRangeAll    DateRange = ""
+	DateRangeToday  DateRange = "today"
+	DateRangeWeek   DateRange = "week"
+	DateRangeCustom DateRange = "custom"
+)
+
+// DateLayout is the format of the custom range dates
+const DateLayout = "2006-01-02"
+
+// FeedPrefs are the view settings remembered for each feed
+type FeedPrefs struct {
+	EntrySort  EntrySort
+	UnreadOnly bool
+	DateRange  DateRange
+	// DateFrom and DateTo bound a custom range, both days included. Either
+	// can be empty to leave that side open.
+	DateFrom string
+	DateTo   string
+}
+
+// EntryQuery selects and orders the entries of a feed
+type EntryQuery struct {
+	FeedID     int64
+	Sort       EntrySort
+	UnreadOnly bool
+	// Since and Until bound the publication date when set, Until excluded
+	Since time.Time
+	Until time.Time
+}
+
+// Query turns the preferences of a feed into the query listing its entries,
+// with relative ranges counted in local days from now.
+func (p FeedPrefs) Query(feedID int64, now time.Time) EntryQuery {
+	q := EntryQuery{FeedID: feedID, Sort: p.EntrySort, UnreadOnly: p.UnreadOnly}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch p.DateRange {
+	case DateRangeToday:
+		q.Since = today
+	case DateRangeWeek:
+		// Weeks start on Monday
+		q.Since = today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	case DateRangeCustom:
+		if from, err := time.ParseInLocation(DateLayout, p.DateFrom, now.Location()); err == nil {
+			q.Since = from
+		}
+		if to, err := time.ParseInLocation(DateLayout, p.DateTo, now.Location()); err == nil {
+			q.Until = to.AddDate(0, 0, 1)
+		}
+	}
+	return q
+}
+
+// where returns the conditions of q on the entries aliased e, the feed
+// condition excepted
+func (q EntryQuery) where() (string, []any) {
+	var conds []string
+	var args []any
+	if q.UnreadOnly {
+		conds = append(conds, "e.read = 0")
+	}
+	// Dates are stored in UTC, so they compare as text
+	if !q.Since.IsZero() {
+		conds = append(conds, "e.published_at >= ?")
+		args = append(args, q.Since.UTC())
+	}
+	if !q.Until.IsZero() {
+		conds = append(conds, "e.published_at < ?")
+		args = append(args, q.Until.UTC())
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " AND " + strings.Join(conds, " AND "), args
 }
 
 var database *sql.DB
 
-func InitDB() error {
+// Path returns the database location for a profile. The default profile
+// (empty name) lives directly in the data dir, named profiles get their own
+// subdirectory so their subscriptions never mix.
+func Path(profile string) (string, error) {
+	dataDir, err := xdg.DataDir()
+	if err != nil {
+		return "", err
+	}
+
+	if profile == "" {
+		fullPath := filepath.Join(dataDir, "rss.db")
+		if err := migrateLegacyDB(fullPath); err != nil {
+			return "", err
+		}
+		return fullPath, nil
+	}
+
+	if strings.ContainsAny(profile, `/\`) || profile == "." || profile == ".." {
+		return "", fmt.Errorf("invalid profile name %q", profile)
+	}
+	return filepath.Join(dataDir, "profiles", profile, "rss.db"), nil
+}
+
+// migrateLegacyDB moves a database from the old ~/.config/lazyrss location
+// to the data dir. That location never followed XDG_CONFIG_HOME. A database
+// that is there but can't be moved is an error, rather than a reason to
+// start over with an empty one.
+func migrateLegacyDB(fullPath string) error {
+	if _, err := os.Stat(fullPath); err == nil {
+		return nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return err
+		// No home, so no old database either
+		return nil
+	}
+	legacyPath := filepath.Join(home, ".config", "lazyrss", "rss.db")
+	if _, err := os.Stat(legacyPath); err != nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("moving the database from %s: %w", legacyPath, err)
+	}
+	if err := os.Rename(legacyPath, fullPath); err != nil {
+		return fmt.Errorf("moving the database from %s: %w", legacyPath, err)
+	}
+	// WAL side files have to travel with the main file
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Rename(legacyPath+suffix, fullPath+suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("moving the database from %s: %w", legacyPath, err)
+		}
 	}
+	return nil
+}
 
-	dbPath := filepath.Join(home, ".config", "lazyrss")
-	if err := os.MkdirAll(dbPath, 0755); err != nil {
+func InitDB(fullPath string) error {
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 		return err
 	}
 
-	fullPath := filepath.Join(dbPath, "rss.db")
 	// Add pragma for WAL mode and busy timeout to handle concurrent access
 	db, err := sql.Open("sqlite", fullPath+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
 	if err != nil {
@@ -69,6 +246,45 @@ func migrate() error {
 	_, _ = database.Exec("ALTER TABLE feeds ADD COLUMN last_read_at DATETIME DEFAULT '1970-01-01 00:00:00'")
 	// Migration to add position
 	_, _ = database.Exec("ALTER TABLE feeds ADD COLUMN position INTEGER DEFAULT 0")
+	// Migration to add folders, pausing and stars
+	_, _ = database.Exec("ALTER TABLE feeds ADD COLUMN folder TEXT NOT NULL DEFAULT ''")
+	_, _ = database.Exec("ALTER TABLE feeds ADD COLUMN paused BOOLEAN NOT NULL DEFAULT 0")
+	_, _ = database.Exec("ALTER TABLE entries ADD COLUMN starred BOOLEAN NOT NULL DEFAULT 0")
+	// Migration to add the trash
+	_, _ = database.Exec("ALTER TABLE feeds ADD COLUMN deleted_at DATETIME")
+	// Migration to add the entry filters
+	_, _ = database.Exec("ALTER TABLE feed_prefs ADD COLUMN unread_only BOOLEAN NOT NULL DEFAULT 0")
+	_, _ = database.Exec("ALTER TABLE feed_prefs ADD COLUMN date_range TEXT NOT NULL DEFAULT ''")
+	_, _ = database.Exec("ALTER TABLE feed_prefs ADD COLUMN date_from TEXT NOT NULL DEFAULT ''")
+	_, _ = database.Exec("ALTER TABLE feed_prefs ADD COLUMN date_to TEXT NOT NULL DEFAULT ''")
+	// Migration to add reading positions
+	_, _ = database.Exec("ALTER TABLE entries ADD COLUMN read_position REAL NOT NULL DEFAULT 0")
+	// Migration to add the recently read list
+	_, _ = database.Exec("ALTER TABLE entries ADD COLUMN read_at DATETIME")
+	_, _ = database.Exec("CREATE INDEX IF NOT EXISTS idx_entries_read_at ON entries(read_at DESC)")
+
+	if done, _ := GetSetting("utc_dates", "false"); done != "true" {
+		if err := normalizeDates(); err != nil {
+			return err
+		}
+		if err := SetSetting("utc_dates", "true"); err != nil {
+			return err
+		}
+	}
+
+	// Entries used to count as read when they were published before the
+	// feed was last opened. Now only the read flag counts, so those entries
+	// get it. Needs the UTC dates above to compare as text.
+	if done, _ := GetSetting("read_flags", "false"); done != "true" {
+		_, err := database.Exec(`UPDATE entries SET read = 1
+			WHERE read = 0 AND substr(published_at, 1, 19) <= (SELECT f.last_read_at FROM feeds f WHERE f.id = entries.feed_id)`)
+		if err != nil {
+			return err
+		}
+		if err := SetSetting("read_flags", "true"); err != nil {
+			return err
+		}
+	}
 
 	// If all positions are 0, initialize them based on current order
 	var count int
@@ -89,6 +305,45 @@ func migrate() error {
 	return nil
 }
 
+// normalizeDates rewrites publication dates in UTC. They used to be stored
+// in the time zone of each feed, which doesn't compare as text, so the date
+// range filter of GetEntries would miss or add entries near its bounds.
+// Dates that can't be read are left as they are.
+func normalizeDates() error {
+	rows, err := database.Query("SELECT id, published_at FROM entries WHERE published_at IS NOT NULL")
+	if err != nil {
+		return err
+	}
+	dates := make(map[int64]time.Time)
+	for rows.Next() {
+		var id int64
+		var v any
+		if err := rows.Scan(&id, &v); err != nil {
+			continue
+		}
+		// The driver parses the dates it can read, the others stay text
+		if t, ok := v.(time.Time); ok {
+			dates[id] = t
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		return err
+	}
+	for id, t := range dates {
+		if _, err := tx.Exec("UPDATE entries SET published_at = ? WHERE id = ?", t.UTC(), id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func createTables() error {
 	queries := []string{
 		`CREATE TABLE IF NOT EXISTS feeds (
@@ -98,7 +353,10 @@ func createTables() error {
 			description TEXT,
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			last_read_at DATETIME DEFAULT '1970-01-01 00:00:00',
-			position INTEGER DEFAULT 0
+			position INTEGER DEFAULT 0,
+			folder TEXT NOT NULL DEFAULT '',
+			paused BOOLEAN NOT NULL DEFAULT 0,
+			deleted_at DATETIME
 		);`,
 		`CREATE TABLE IF NOT EXISTS entries (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -109,9 +367,21 @@ func createTables() error {
 			content TEXT,
 			published_at DATETIME,
 			read BOOLEAN DEFAULT 0,
+			starred BOOLEAN NOT NULL DEFAULT 0,
+			read_position REAL NOT NULL DEFAULT 0,
+			read_at DATETIME,
 			FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_entries_feed_id ON entries(feed_id, published_at DESC);`,
+		`CREATE INDEX IF NOT EXISTS idx_entries_unread ON entries(read, published_at DESC, id DESC);`,
+		`CREATE TABLE IF NOT EXISTS feed_prefs (
+			feed_id INTEGER PRIMARY KEY,
+			entry_sort TEXT NOT NULL DEFAULT 'newest',
+			unread_only BOOLEAN NOT NULL DEFAULT 0,
+			date_range TEXT NOT NULL DEFAULT '',
+			date_from TEXT NOT NULL DEFAULT '',
+			date_to TEXT NOT NULL DEFAULT ''
+		);`,
 		`CREATE TABLE IF NOT EXISTS settings (
 			key TEXT PRIMARY KEY,
 			value TEXT NOT NULL
@@ -126,12 +396,17 @@ func createTables() error {
 	return nil
 }
 
-func GetFeeds() ([]Feed, error) {
+func GetFeeds(sort FeedSort) ([]Feed, error) {
+	order, ok := feedOrders[sort]
+	if !ok {
+		order = feedOrders[FeedSortManual]
+	}
 	query := `
-		SELECT f.id, f.url, f.title, f.description, f.created_at, f.last_read_at, f.position,
-		       (SELECT COUNT(*) FROM entries e WHERE e.feed_id = f.id AND e.published_at > f.last_read_at) as unread_count
+		SELECT f.id, f.url, f.title, f.description, f.created_at, f.last_read_at, f.position, f.folder, f.paused,
+		       (SELECT COUNT(*) FROM entries e WHERE e.feed_id = f.id AND e.read = 0) as unread_count
 		FROM feeds f 
-		ORDER BY f.position ASC, f.title ASC`
+		WHERE f.deleted_at IS NULL
+		ORDER BY f.folder COLLATE NOCASE ASC, ` + order
 	rows, err := database.Query(query)
 	if err != nil {
 		return nil, err
@@ -141,7 +416,7 @@ func GetFeeds() ([]Feed, error) {
 	var feeds []Feed
 	for rows.Next() {
 		var f Feed
-		if err := rows.Scan(&f.ID, &f.URL, &f.Title, &f.Description, &f.CreatedAt, &f.LastReadAt, &f.Position, &f.UnreadCount); err != nil {
+		if err := rows.Scan(&f.ID, &f.URL, &f.Title, &f.Description, &f.CreatedAt, &f.LastReadAt, &f.Position, &f.Folder, &f.Paused, &f.UnreadCount); err != nil {
 			return nil, err
 		}
 		feeds = append(feeds, f)
@@ -167,26 +442,77 @@ func SwapFeedPositions(idA, posA, idB, posB int) error {
 	return tx.Commit()
 }
 
-func MarkFeedAsRead(id int64) error {
-	_, err := database.Exec("UPDATE feeds SET last_read_at = CURRENT_TIMESTAMP WHERE id = ?", id)
-	return err
-}
-
+// AddFeed subscribes to a feed. A feed that is in the trash is restored
+// with its entries instead.
 func AddFeed(url, title, desc string) (int64, error) {
+	if _, err := database.Exec("UPDATE feeds SET deleted_at = NULL WHERE url = ?", url); err != nil {
+		return 0, err
+	}
 	var maxPos int
 	database.QueryRow("SELECT COALESCE(MAX(position), -1) FROM feeds").Scan(&maxPos)
-	res, err := database.Exec("INSERT OR IGNORE INTO feeds (url, title, description, position) VALUES (?, ?, ?, ?)", url, title, desc, maxPos+1)
+	_, err := database.Exec("INSERT OR IGNORE INTO feeds (url, title, description, position) VALUES (?, ?, ?, ?)", url, title, desc, maxPos+1)
 	if err != nil {
 		return 0, err
 	}
-	return res.LastInsertId()
+	var id int64
+	err = database.QueryRow("SELECT id FROM feeds WHERE url = ?", url).Scan(&id)
+	return id, err
 }
 
+// DeleteFeed moves a feed to the trash, where it stays with its entries
+// until PurgeDeletedFeeds removes it.
 func DeleteFeed(id int64) error {
-	_, err := database.Exec("DELETE FROM feeds WHERE id = ?", id)
+	_, err := database.Exec("UPDATE feeds SET deleted_at = CURRENT_TIMESTAMP WHERE id = ?", id)
 	return err
 }
 
+// RestoreFeeds takes feeds out of the trash
+func RestoreFeeds(ids []int64) error {
+	return updateIDs("UPDATE feeds SET deleted_at = NULL WHERE id = ?", ids)
+}
+
+// GetDeletedFeeds returns the feeds in the trash, most recently deleted first
+func GetDeletedFeeds() ([]Feed, error) {
+	rows, err := database.Query("SELECT id, url, title, description, created_at, folder FROM feeds WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feeds []Feed
+	for rows.Next() {
+		var f Feed
+		if err := rows.Scan(&f.ID, &f.URL, &f.Title, &f.Description, &f.CreatedAt, &f.Folder); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, f)
+	}
+	return feeds, rows.Err()
+}
+
+// PurgeDeletedFeeds removes for good the feeds that have been in the trash
+// for longer than keep, with their entries and preferences.
+func PurgeDeletedFeeds(keep time.Duration) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return err
+	}
+	// Foreign keys aren't enforced, so entries are removed explicitly
+	expired := "SELECT id FROM feeds WHERE deleted_at IS NOT NULL AND deleted_at <= datetime('now', ?)"
+	cutoff := fmt.Sprintf("-%d seconds", int64(keep.Seconds()))
+	for _, query := range []string{
+		"DELETE FROM entries WHERE feed_id IN (" + expired + ")",
+		"DELETE FROM feed_prefs WHERE feed_id IN (" + expired + ")",
+		"DELETE FROM feeds WHERE id IN (" + expired + ")",
+	} {
+		if _, err := tx.Exec(query, cutoff); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func SaveEntries(feedID int64, entries []Entry) error {
 	tx, err := database.Begin()
 	if err != nil {
@@ -202,7 +528,7 @@ func SaveEntries(feedID int64, entries []Entry) error {
 	defer stmt.Close()
 
 	for _, e := range entries {
-		_, err := stmt.Exec(feedID, e.Title, e.Link, e.Description, e.Content, e.PublishedAt)
+		_, err := stmt.Exec(feedID, e.Title, e.Link, e.Description, e.Content, e.PublishedAt.UTC())
 		if err != nil {
 			tx.Rollback()
 			return err
@@ -212,8 +538,14 @@ func SaveEntries(feedID int64, entries []Entry) error {
 	return tx.Commit()
 }
 
-func GetEntries(feedID int64) ([]Entry, error) {
-	rows, err := database.Query("SELECT id, feed_id, title, link, description, content, published_at, read FROM entries WHERE feed_id = ? ORDER BY published_at DESC", feedID)
+func GetEntries(q EntryQuery) ([]Entry, error) {
+	order, ok := entryOrders[q.Sort]
+	if !ok {
+		order = entryOrders[EntrySortNewest]
+	}
+	where, args := q.where()
+	rows, err := database.Query("SELECT e.id, e.feed_id, e.title, e.link, e.description, e.content, e.published_at, e.read, e.starred FROM entries e WHERE e.feed_id = ?"+where+" ORDER BY "+order,
+		append([]any{q.FeedID}, args...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +554,7 @@ func GetEntries(feedID int64) ([]Entry, error) {
 	var entries []Entry
 	for rows.Next() {
 		var e Entry
-		if err := rows.Scan(&e.ID, &e.FeedID, &e.Title, &e.Link, &e.Description, &e.Content, &e.PublishedAt, &e.Read); err != nil {
+		if err := rows.Scan(&e.ID, &e.FeedID, &e.Title, &e.Link, &e.Description, &e.Content, &e.PublishedAt, &e.Read, &e.Starred); err != nil {
 			return nil, err
 		}
 		entries = append(entries, e)
@@ -230,11 +562,232 @@ func GetEntries(feedID int64) ([]Entry, error) {
 	return entries, nil
 }
 
+// GetUnreadEntries returns a page of unread entries across all feeds, newest
+// first, or oldest first with EntrySortOldest. The feed of q is ignored.
+// Pages are keyed on the last entry of the previous page rather than an
+// offset, so deep pages stay as cheap as the first one; pass a nil after for
+// the first page.
+func GetUnreadEntries(q EntryQuery, after *Entry, limit int) ([]Entry, error) {
+	cmp, dir := "<", "DESC"
+	if q.Sort == EntrySortOldest {
+		cmp, dir = ">", "ASC"
+	}
+	q.UnreadOnly = false
+	where, args := q.where()
+	query := `SELECT e.id, e.feed_id, e.title, e.link, e.description, e.content, e.published_at, e.read, e.starred, f.title
+		FROM entries e JOIN feeds f ON f.id = e.feed_id
+		WHERE e.read = 0 AND f.deleted_at IS NULL` + where
+	if after != nil {
+		query += ` AND (e.published_at ` + cmp + ` ? OR (e.published_at = ? AND e.id ` + cmp + ` ?))`
+		args = append(args, after.PublishedAt.UTC(), after.PublishedAt.UTC(), after.ID)
+	}
+	query += ` ORDER BY e.published_at ` + dir + `, e.id ` + dir + ` LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.FeedID, &e.Title, &e.Link, &e.Description, &e.Content, &e.PublishedAt, &e.Read, &e.Starred, &e.FeedTitle); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// CountUnread returns the number of unread entries across all feeds
+func CountUnread() (int, error) {
+	var count int
+	err := database.QueryRow("SELECT COUNT(*) FROM entries e JOIN feeds f ON f.id = e.feed_id WHERE e.read = 0 AND f.deleted_at IS NULL").Scan(&count)
+	return count, err
+}
+
+// GetUnreadFeedIDs returns the set of feeds that have unread entries
+func GetUnreadFeedIDs() (map[int64]bool, error) {
+	rows, err := database.Query("SELECT DISTINCT feed_id FROM entries WHERE read = 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+// MarkAsRead flags an entry read when it is opened, and records when for
+// the recently read list
 func MarkAsRead(entryID int64) error {
-	_, err := database.Exec("UPDATE entries SET read = 1 WHERE id = ?", entryID)
+	_, err := database.Exec("UPDATE entries SET read = 1, read_at = ? WHERE id = ?", time.Now().UTC(), entryID)
+	return err
+}
+
+// GetRecentlyRead returns the last entries opened, the latest first
+func GetRecentlyRead(limit int) ([]Entry, error) {
+	rows, err := database.Query(`SELECT e.id, e.feed_id, e.title, e.link, e.description, e.content, e.published_at, e.read, e.starred, f.title
+		FROM entries e JOIN feeds f ON f.id = e.feed_id
+		WHERE e.read_at IS NOT NULL AND f.deleted_at IS NULL
+		ORDER BY e.read_at DESC, e.id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.ID, &e.FeedID, &e.Title, &e.Link, &e.Description, &e.Content, &e.PublishedAt, &e.Read, &e.Starred, &e.FeedTitle); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetReadingPosition returns how far an entry was scrolled when it was last
+// left, from 0 at the top to 1 at the bottom
+func GetReadingPosition(entryID int64) (float64, error) {
+	var pos float64
+	err := database.QueryRow("SELECT read_position FROM entries WHERE id = ?", entryID).Scan(&pos)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return pos, err
+}
+
+func SetReadingPosition(entryID int64, pos float64) error {
+	_, err := database.Exec("UPDATE entries SET read_position = ? WHERE id = ?", pos, entryID)
+	return err
+}
+
+// updateIDs runs query, whose last parameter is an id, once for each of ids
+// in a single transaction
+func updateIDs(query string, ids []int64, args ...any) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(query)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+	for _, id := range ids {
+		if _, err := stmt.Exec(append(args, id)...); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func SetEntriesRead(ids []int64, read bool) error {
+	return updateIDs("UPDATE entries SET read = ? WHERE id = ?", ids, read)
+}
+
+func SetEntriesStarred(ids []int64, starred bool) error {
+	return updateIDs("UPDATE entries SET starred = ? WHERE id = ?", ids, starred)
+}
+
+func SetFeedsFolder(ids []int64, folder string) error {
+	return updateIDs("UPDATE feeds SET folder = ? WHERE id = ?", ids, folder)
+}
+
+func SetFeedsPaused(ids []int64, paused bool) error {
+	return updateIDs("UPDATE feeds SET paused = ? WHERE id = ?", ids, paused)
+}
+
+// MarkAllAsRead marks every entry of a feed as read, or the entries of all
+// feeds when feedID is 0. It returns the entries that were unread, to undo
+// it.
+func MarkAllAsRead(feedID int64) ([]int64, error) {
+	if feedID < 0 {
+		return nil, fmt.Errorf("feed %d doesn't exist", feedID)
+	}
+	tx, err := database.Begin()
+	if err != nil {
+		return nil, err
+	}
+	where, feedsQuery := "read = 0", "UPDATE feeds SET last_read_at = CURRENT_TIMESTAMP"
+	var args []any
+	if feedID != 0 {
+		where += " AND feed_id = ?"
+		feedsQuery += " WHERE id = ?"
+		args = append(args, feedID)
+	}
+	rows, err := tx.Query("SELECT id FROM entries WHERE "+where, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			tx.Rollback()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if _, err := tx.Exec("UPDATE entries SET read = 1 WHERE "+where, args...); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if _, err := tx.Exec(feedsQuery, args...); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	return ids, tx.Commit()
+}
+
+// GetFeedPrefs returns the view settings of a feed, the defaults when none
+// were saved
+func GetFeedPrefs(feedID int64) (FeedPrefs, error) {
+	prefs := FeedPrefs{EntrySort: EntrySortNewest}
+	err := database.QueryRow("SELECT entry_sort, unread_only, date_range, date_from, date_to FROM feed_prefs WHERE feed_id = ?", feedID).
+		Scan(&prefs.EntrySort, &prefs.UnreadOnly, &prefs.DateRange, &prefs.DateFrom, &prefs.DateTo)
+	if err == sql.ErrNoRows {
+		return prefs, nil
+	}
+	if _, ok := entryOrders[prefs.EntrySort]; !ok {
+		prefs.EntrySort = EntrySortNewest
+	}
+	return prefs, err
+}
+
+func SetFeedPrefs(feedID int64, prefs FeedPrefs) error {
+	_, err := database.Exec("INSERT OR REPLACE INTO feed_prefs (feed_id, entry_sort, unread_only, date_range, date_from, date_to) VALUES (?, ?, ?, ?, ?, ?)",
+		feedID, prefs.EntrySort, prefs.UnreadOnly, prefs.DateRange, prefs.DateFrom, prefs.DateTo)
 	return err
 }
 
+func GetFeedSort() (FeedSort, error) {
+	value, err := GetSetting("feed_sort", string(FeedSortManual))
+	if _, ok := feedOrders[FeedSort(value)]; !ok {
+		return FeedSortManual, err
+	}
+	return FeedSort(value), err
+}
+
+func SetFeedSort(sort FeedSort) error {
+	return SetSetting("feed_sort", string(sort))
+}
+
 func GetSetting(key string, defaultValue string) (string, error) {
 	var value string
 	err := database.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
@@ -268,6 +821,50 @@ func SetShowArticleView(show bool) error {
 	return SetSetting("show_article_view", value)
 }
 
+// GetLayoutRatios returns the pane widths saved after resizing, as shares
+// of the window. They are 0 when the panes were never resized.
+func GetLayoutRatios() (feeds, entries float64, err error) {
+	value, err := GetSetting("layout_ratios", "")
+	if err != nil || value == "" {
+		return 0, 0, err
+	}
+	if _, err := fmt.Sscanf(value, "%g %g", &feeds, &entries); err != nil {
+		return 0, 0, nil
+	}
+	return feeds, entries, nil
+}
+
+func SetLayoutRatios(feeds, entries float64) error {
+	if feeds == 0 && entries == 0 {
+		return SetSetting("layout_ratios", "")
+	}
+	return SetSetting("layout_ratios", fmt.Sprintf("%.3f %.3f", feeds, entries))
+}
+
+// Session is what was selected when lazyrss was last quit, to start there
+// again
+type Session struct {
+	FeedID  int64
+	EntryID int64
+	Pane    int
+}
+
+func GetSession() (Session, error) {
+	var s Session
+	value, err := GetSetting("session", "")
+	if err != nil || value == "" {
+		return s, err
+	}
+	if _, err := fmt.Sscanf(value, "%d %d %d", &s.FeedID, &s.EntryID, &s.Pane); err != nil {
+		return Session{}, nil
+	}
+	return s, nil
+}
+
+func SetSession(s Session) error {
+	return SetSetting("session", fmt.Sprintf("%d %d %d", s.FeedID, s.EntryID, s.Pane))
+}
+
 func GetShowEntryDates() (bool, error) {
 	value, err := GetSetting("show_entry_dates", "false")
 	if err != nil {
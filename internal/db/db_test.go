@@ -0,0 +1,240 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPathMovesLegacyDB(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+	// The old location never followed XDG_CONFIG_HOME
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "elsewhere"))
+	legacy := filepath.Join(home, ".config", "lazyrss", "rss.db")
+	if err := os.MkdirAll(filepath.Dir(legacy), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{legacy, legacy + "-wal"} {
+		if err := os.WriteFile(name, []byte("db"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path, err := Path("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "data", "lazyrss", "rss.db"); path != want {
+		t.Errorf("Path = %q, want %q", path, want)
+	}
+	for _, name := range []string{path, path + "-wal"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("%s wasn't moved: %v", filepath.Base(name), err)
+		}
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("old database still there")
+	}
+}
+
+func TestPathProfiles(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	for _, name := range []string{"../x", "a/b", ".", ".."} {
+		if _, err := Path(name); err == nil {
+			t.Errorf("Path(%q) accepted", name)
+		}
+	}
+	path, err := Path("work")
+	if err != nil || filepath.Base(filepath.Dir(path)) != "work" {
+		t.Errorf("Path(work) = %q, %v", path, err)
+	}
+}
+
+func TestFeedPrefsQuery(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	// A Wednesday afternoon
+	now := time.Date(2024, 3, 13, 15, 4, 5, 0, paris)
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, paris) }
+
+	tests := []struct {
+		name         string
+		prefs        FeedPrefs
+		since, until time.Time
+	}{
+		{"all", FeedPrefs{}, time.Time{}, time.Time{}},
+		{"today", FeedPrefs{DateRange: DateRangeToday}, day(2024, 3, 13), time.Time{}},
+		{"week starts on monday", FeedPrefs{DateRange: DateRangeWeek}, day(2024, 3, 11), time.Time{}},
+		{"custom", FeedPrefs{DateRange: DateRangeCustom, DateFrom: "2024-01-01", DateTo: "2024-01-31"}, day(2024, 1, 1), day(2024, 2, 1)},
+		{"custom open start", FeedPrefs{DateRange: DateRangeCustom, DateTo: "2024-01-31"}, time.Time{}, day(2024, 2, 1)},
+		{"custom bad date", FeedPrefs{DateRange: DateRangeCustom, DateFrom: "soon"}, time.Time{}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.prefs.Query(7, now)
+			if q.FeedID != 7 {
+				t.Errorf("FeedID = %d, want 7", q.FeedID)
+			}
+			if !q.Since.Equal(tt.since) || !q.Until.Equal(tt.until) {
+				t.Errorf("range = %v - %v, want %v - %v", q.Since, q.Until, tt.since, tt.until)
+			}
+		})
+	}
+
+	// Sunday still belongs to the week started on Monday
+	q := FeedPrefs{DateRange: DateRangeWeek}.Query(0, time.Date(2024, 3, 17, 23, 0, 0, 0, paris))
+	if want := day(2024, 3, 11); !q.Since.Equal(want) {
+		t.Errorf("week of a Sunday starts %v, want %v", q.Since, want)
+	}
+}
+
+func TestEntryQueryWhere(t *testing.T) {
+	since := time.Date(2024, 3, 13, 0, 0, 0, 0, time.FixedZone("CET", 3600))
+	where, args := EntryQuery{UnreadOnly: true, Since: since}.where()
+	if want := " AND e.read = 0 AND e.published_at >= ?"; where != want {
+		t.Errorf("where = %q, want %q", where, want)
+	}
+	if len(args) != 1 || args[0].(time.Time).Location() != time.UTC {
+		t.Errorf("args = %v, want one UTC time", args)
+	}
+	if where, args := (EntryQuery{}).where(); where != "" || args != nil {
+		t.Errorf("empty query gives %q %v", where, args)
+	}
+}
+
+func TestReadFlagsMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rss.db")
+	if err := InitDB(path); err != nil {
+		t.Fatal(err)
+	}
+	feedID, err := AddFeed("http://example.com/feed", "Example", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opened := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+	err = SaveEntries(feedID, []Entry{
+		{Title: "seen", Link: "http://example.com/1", PublishedAt: opened.Add(-time.Hour)},
+		{Title: "new", Link: "http://example.com/2", PublishedAt: opened.Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A database from before the read flags: the feed was opened once
+	database.Exec("UPDATE feeds SET last_read_at = ?", opened.Format("2006-01-02 15:04:05"))
+	database.Exec("DELETE FROM settings WHERE key = 'read_flags'")
+	database.Close()
+	if err := InitDB(path); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := GetEntries(EntryQuery{FeedID: feedID, Sort: EntrySortOldest})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("entries %v, %v", entries, err)
+	}
+	if !entries[0].Read || entries[1].Read {
+		t.Errorf("read flags %v %v, want the entry published before the visit read", entries[0].Read, entries[1].Read)
+	}
+	feeds, err := GetFeeds(FeedSortManual)
+	if err != nil || len(feeds) != 1 || feeds[0].UnreadCount != 1 {
+		t.Errorf("feeds %v, %v, want one unread entry", feeds, err)
+	}
+}
+
+// openTestDB opens an empty database with a feed of three entries, the
+// last one read
+func openTestDB(t *testing.T) (feedID int64, entryIDs []int64) {
+	t.Helper()
+	if err := InitDB(filepath.Join(t.TempDir(), "rss.db")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	feedID, err := AddFeed("http://example.com/feed", "Example", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+	var entries []Entry
+	for i := range 3 {
+		entries = append(entries, Entry{Title: fmt.Sprint("entry ", i), Link: fmt.Sprint("http://example.com/", i), PublishedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+	if err := SaveEntries(feedID, entries); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := GetEntries(EntryQuery{FeedID: feedID, Sort: EntrySortOldest})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range saved {
+		entryIDs = append(entryIDs, e.ID)
+	}
+	if err := MarkAsRead(entryIDs[2]); err != nil {
+		t.Fatal(err)
+	}
+	return feedID, entryIDs
+}
+
+func TestTrash(t *testing.T) {
+	feedID, _ := openTestDB(t)
+	if err := DeleteFeed(feedID); err != nil {
+		t.Fatal(err)
+	}
+	if feeds, _ := GetFeeds(FeedSortManual); len(feeds) != 0 {
+		t.Errorf("deleted feed still listed: %v", feeds)
+	}
+	if deleted, _ := GetDeletedFeeds(); len(deleted) != 1 || deleted[0].ID != feedID {
+		t.Errorf("trash holds %v", deleted)
+	}
+	// Kept for a while, the trash isn't emptied
+	if err := PurgeDeletedFeeds(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := RestoreFeeds([]int64{feedID}); err != nil {
+		t.Fatal(err)
+	}
+	feeds, _ := GetFeeds(FeedSortManual)
+	if len(feeds) != 1 || feeds[0].UnreadCount != 2 {
+		t.Fatalf("restored feeds %v, want the feed with its read state", feeds)
+	}
+
+	if err := DeleteFeed(feedID); err != nil {
+		t.Fatal(err)
+	}
+	if err := PurgeDeletedFeeds(0); err != nil {
+		t.Fatal(err)
+	}
+	if deleted, _ := GetDeletedFeeds(); len(deleted) != 0 {
+		t.Errorf("trash not emptied: %v", deleted)
+	}
+	if entries, _ := GetEntries(EntryQuery{FeedID: feedID}); len(entries) != 0 {
+		t.Errorf("entries of a purged feed left: %v", entries)
+	}
+}
+
+func TestMarkAllAsRead(t *testing.T) {
+	feedID, ids := openTestDB(t)
+	if _, err := MarkAllAsRead(-1); err == nil {
+		t.Error("marked a virtual feed read")
+	}
+	marked, err := MarkAllAsRead(feedID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The entry read already isn't reported, undoing leaves it read
+	slices.Sort(marked)
+	if len(marked) != 2 || marked[0] != ids[0] || marked[1] != ids[1] {
+		t.Errorf("marked %v, want %v", marked, ids[:2])
+	}
+	if n, _ := CountUnread(); n != 0 {
+		t.Errorf("%d unread left", n)
+	}
+	if marked, _ := MarkAllAsRead(0); len(marked) != 0 {
+		t.Errorf("marked %v again", marked)
+	}
+}
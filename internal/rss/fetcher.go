@@ -3,14 +3,50 @@ package rss
 import (
 	"github.com/jeremiev/lazyrss/internal/db"
 	"context"
+	"net/http"
+	"net/url"
+	"sync/atomic"
 	"time"
 
 	"github.com/mmcdole/gofeed"
 )
 
+// httpSettings are the HTTP options of the fetches. They are replaced as a
+// whole when the config is reloaded, never changed, since fetches read them
+// from other goroutines.
+type httpSettings struct {
+	client    *http.Client
+	timeout   time.Duration
+	userAgent string
+}
+
+var settings atomic.Pointer[httpSettings]
+
+func init() {
+	settings.Store(&httpSettings{client: &http.Client{}, timeout: 10 * time.Second, userAgent: "lazyrss"})
+}
+
+// Configure sets the HTTP options used for every subsequent fetch. An empty
+// proxy falls back to the usual HTTP_PROXY environment variables.
+func Configure(t time.Duration, ua, proxy string) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy != "" {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return err
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	settings.Store(&httpSettings{client: &http.Client{Transport: transport}, timeout: t, userAgent: ua})
+	return nil
+}
+
 func FetchFeed(url string) (*gofeed.Feed, error) {
+	s := settings.Load()
 	fp := gofeed.NewParser()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	fp.Client = s.client
+	fp.UserAgent = s.userAgent
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
 	defer cancel()
 	return fp.ParseURLWithContext(url, ctx)
 }
@@ -0,0 +1,54 @@
+package rss
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { Configure(10*time.Second, "lazyrss", "") })
+	if err := Configure(time.Second, "test", "http://proxy.example:3128"); err != nil {
+		t.Errorf("valid proxy: %v", err)
+	}
+	if err := Configure(time.Second, "test", "http://bad host:3128"); err == nil {
+		t.Error("invalid proxy accepted")
+	}
+}
+
+// Reloading the config changes the HTTP settings while fetches run
+func TestConfigureWhileFetching(t *testing.T) {
+	t.Cleanup(func() { Configure(10*time.Second, "lazyrss", "") })
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<title>%s</title><main>hi</main>", r.UserAgent())
+	}))
+	defer srv.Close()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			Configure(5*time.Second, fmt.Sprint("agent", i), "")
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := FetchPage(srv.URL); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	page, err := FetchPage(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Title) < len("agent") || page.Title[:5] != "agent" {
+		t.Errorf("fetched with user agent %q", page.Title)
+	}
+}
@@ -0,0 +1,60 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+)
+
+const (
+	// maxImageSize is the largest image FetchImage downloads, in bytes
+	maxImageSize = 10 << 20
+	// maxImagePixels is the largest image FetchImage decodes, in pixels: a
+	// small file can hold a huge image, which takes 4 bytes a pixel decoded
+	maxImagePixels = 16 << 20
+)
+
+// FetchImage downloads and decodes a PNG, JPEG or GIF image with the same
+// HTTP options as the feeds.
+func FetchImage(url string) (image.Image, error) {
+	s := settings.Load()
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	if resp.ContentLength > maxImageSize {
+		return nil, fmt.Errorf("%s: image too large", url)
+	}
+	// The header is read first to check the size, then again to decode
+	body := io.LimitReader(resp.Body, maxImageSize)
+	var header bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(body, &header))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("%s: image too large (%dx%d)", url, cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(io.MultiReader(&header, body))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", url, err)
+	}
+	return img, nil
+}
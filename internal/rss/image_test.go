@@ -0,0 +1,68 @@
+package rss
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pngOfSize returns a PNG whose header gives it w×h pixels. Only the
+// header is right for a large size, the pixels are those of a 1×1 image.
+func pngOfSize(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	// The IHDR chunk follows the 8 bytes signature: length, type, then
+	// width and height, with a CRC of the type and data after them
+	binary.BigEndian.PutUint32(b[16:], w)
+	binary.BigEndian.PutUint32(b[20:], h)
+	binary.BigEndian.PutUint32(b[29:], crc32.ChecksumIEEE(b[12:29]))
+	return b
+}
+
+func TestFetchImage(t *testing.T) {
+	var small bytes.Buffer
+	if err := png.Encode(&small, image.NewNRGBA(image.Rect(0, 0, 30, 20))); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		body    []byte
+		wantErr string
+	}{
+		{"small image", small.Bytes(), ""},
+		{"too many pixels", pngOfSize(t, 100000, 100000), "image too large (100000x100000)"},
+		{"not an image", []byte("<html></html>"), "unknown format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write(tt.body)
+			}))
+			defer srv.Close()
+
+			img, err := FetchImage(srv.URL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b := img.Bounds(); b.Dx() != 30 || b.Dy() != 20 {
+				t.Errorf("image of %v, want 30x20", b)
+			}
+		})
+	}
+}
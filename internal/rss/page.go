@@ -0,0 +1,59 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Page is a web page reduced to its main content
+type Page struct {
+	Title string
+	// HTML of the <article> or <main> element, or of the whole body
+	HTML string
+}
+
+// FetchPage downloads an HTML page with the same HTTP options as the feeds.
+func FetchPage(url string) (*Page, error) {
+	s := settings.Load()
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.userAgent)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt != "" && mt != "text/html" && mt != "application/xhtml+xml" {
+		return nil, fmt.Errorf("%s: not a web page (%s)", url, mt)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	doc.Find("script, style, noscript, nav, aside, form").Remove()
+	content := doc.Find("article").First()
+	if content.Length() == 0 {
+		content = doc.Find("main").First()
+	}
+	if content.Length() == 0 {
+		content = doc.Find("body")
+	}
+	html, err := content.Html()
+	if err != nil {
+		return nil, err
+	}
+	return &Page{Title: strings.TrimSpace(doc.Find("title").First().Text()), HTML: html}, nil
+}
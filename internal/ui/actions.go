@@ -0,0 +1,430 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paneScope returns the keymap scope of the active pane
+func (m Model) paneScope() keyScope {
+	switch m.activePane {
+	case paneEntries:
+		return scopeEntries
+	case paneContent:
+		return scopeContent
+	}
+	return scopeFeeds
+}
+
+// handleMainKey resolves count prefixes and key sequences in the main view
+// and runs the action they are bound to.
+func (m Model) handleMainKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	// A list that is taking filter input gets every key
+	if m.activePane == paneFeeds && m.feedsList.FilterState() == list.Filtering {
+		m.feedsList, cmd = m.feedsList.Update(msg)
+		return m, cmd
+	}
+	if m.activePane == paneEntries && m.entriesList.FilterState() == list.Filtering {
+		m.entriesList, cmd = m.entriesList.Update(msg)
+		return m, cmd
+	}
+
+	k := msg.String()
+	if k == " " {
+		k = "space"
+	}
+
+	if m.pendingKeys == "" && len(k) == 1 && k[0] >= '0' && k[0] <= '9' && (k != "0" || m.count > 0) {
+		m.count = m.count*10 + int(k[0]-'0')
+		return m, nil
+	}
+
+	seq := k
+	if m.pendingKeys != "" {
+		seq = m.pendingKeys + " " + k
+	}
+	action, prefix := m.keys.match(seq, m.paneScope())
+	if prefix {
+		m.pendingKeys = seq
+		return m, nil
+	}
+
+	count := m.count
+	m.pendingKeys = ""
+	m.count = 0
+	if action == "" {
+		if seq == "esc" {
+			// Esc clears the selection, then the article search, then an
+			// applied filter, when it isn't bound to anything. It then leaves
+			// the reader, or goes back up to the previous pane when only one
+			// is shown.
+			switch {
+			case m.clearSelection() || m.clearSearch() || m.clearFilter():
+			case m.reader:
+				return m.toggleReader()
+			case m.currentLayout() == config.LayoutSingle:
+				m.drillUp()
+			}
+		}
+		return m, nil
+	}
+	return m.runAction(action, count)
+}
+
+// runAction performs a named action. count is the numeric prefix typed
+// before it, 0 when there was none.
+func (m Model) runAction(action string, count int) (tea.Model, tea.Cmd) {
+	switch action {
+	case "help":
+		m.previousState = m.state
+		m.state = stateHelp
+		return m, nil
+	case "quit":
+		return m, tea.Sequence(m.saveSession(), tea.Quit)
+	case "command_line":
+		m.openCommandLine("")
+		return m, nil
+	case "open":
+		// With a single pane, enter goes down to the pane that isn't shown
+		if m.currentLayout() == config.LayoutSingle && !m.reader && m.drillDown() {
+			return m, nil
+		}
+		if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
+			return m, m.openURL(i.entry.Link)
+		}
+		return m, nil
+	case "link_hints":
+		return m.startLinkHint(count)
+	case "yank_link":
+		return m.yank("link")
+	case "yank_title":
+		return m.yank("title")
+	case "yank_feed_url":
+		return m.yank("feed")
+	case "yank_markdown":
+		return m.yank("markdown")
+	case "open_pager":
+		return m.openInPager()
+	case "open_editor":
+		return m.openInEditor()
+	case "pipe":
+		m.openCommandLine("pipe ")
+		return m, nil
+	case "back":
+		return m.goBack(false)
+	case "forward":
+		return m.goBack(true)
+	case "next_pane":
+		numPanes := 3
+		if !m.showArticleView {
+			numPanes = 2
+		}
+		m.activePane = state((int(m.activePane) + 1) % numPanes)
+		return m, nil
+	case "prev_pane":
+		numPanes := 3
+		if !m.showArticleView {
+			numPanes = 2
+		}
+		m.activePane = state((int(m.activePane) - 1 + numPanes) % numPanes)
+		return m, nil
+	case "add_feed":
+		m.state = stateAddingFeed
+		m.textInput.Focus()
+		return m, nil
+	case "import_opml":
+		m.state = stateImportingOPML
+		return m, m.filePicker.Init()
+	case "export_opml":
+		return m, m.exportOPML
+	case "toggle_feed_info":
+		m.showFeedInfo = !m.showFeedInfo
+		return m, nil
+	case "grow_pane":
+		return m.resizePane(max(count, 1))
+	case "shrink_pane":
+		return m.resizePane(-max(count, 1))
+	case "toggle_maximize":
+		return m.toggleMaximize()
+	case "toggle_images":
+		return m.toggleImages()
+	case "toggle_reader":
+		return m.toggleReader()
+	case "next_article":
+		return m.stepArticle(max(count, 1))
+	case "prev_article":
+		return m.stepArticle(-max(count, 1))
+	case "cycle_layout":
+		i := slices.Index(config.LayoutModes, m.layoutMode)
+		return m.setLayout(config.LayoutModes[(i+1)%len(config.LayoutModes)])
+	case "toggle_article_view":
+		m.showArticleView = !m.showArticleView
+		if !m.showArticleView && m.activePane == paneContent {
+			m.activePane = paneEntries
+		}
+		cmd := m.relayout()
+		return m, tea.Batch(cmd, m.saveShowArticleView(m.showArticleView))
+	case "toggle_dates":
+		m.showEntryDates = !m.showEntryDates
+		// Refresh entries list to update titles with dates
+		if m.currentFeed.ID != 0 {
+			return m, tea.Batch(m.loadEntries(m.currentFeed), m.saveShowEntryDates(m.showEntryDates))
+		}
+		return m, m.saveShowEntryDates(m.showEntryDates)
+	case "cycle_theme":
+		names := themeNames(m.cfg)
+		next := names[0]
+		for i, name := range names {
+			if name == m.theme.Name {
+				next = names[(i+1)%len(names)]
+			}
+		}
+		t, err := resolveTheme(next, m.cfg, m.darkBackground)
+		if err != nil {
+			return m.commandError("%v", err)
+		}
+		m.setTheme(t)
+		m.statusMsg = "Theme: " + next
+		return m, m.rerender()
+	case "next_unread":
+		return m.jumpUnread(true)
+	case "prev_unread":
+		return m.jumpUnread(false)
+	case "mark_read_next":
+		return m.markReadNext()
+	case "cycle_sort":
+		return m.cycleSort()
+	case "toggle_unread_only":
+		return m.toggleUnreadOnly()
+	case "cycle_date_range":
+		return m.cycleDateRange()
+	case "set_date_range":
+		m.openCommandLine("date_range ")
+		return m, nil
+	case "refresh_all":
+		return m, m.refreshAllFeeds()
+	case "refresh_feed":
+		return m, m.refreshCurrentFeed()
+
+	case "move_feed_up":
+		if m.feedSort != db.FeedSortManual {
+			m.statusMsg = "Feeds can only be moved in manual order"
+			return m, nil
+		}
+		idx := m.feedsList.Index()
+		if idx > 0 {
+			itemA := m.feedsList.Items()[idx].(feedItem)
+			itemB := m.feedsList.Items()[idx-1].(feedItem)
+			if isVirtualFeed(itemA.feed) || isVirtualFeed(itemB.feed) || itemA.feed.Folder != itemB.feed.Folder {
+				return m, nil
+			}
+			posA, posB := itemA.feed.Position, itemB.feed.Position
+			if posA == posB {
+				posA, posB = idx, idx-1
+			}
+			db.SwapFeedPositions(int(itemA.feed.ID), posA, int(itemB.feed.ID), posB)
+			return m, m.loadFeedsWithIndex(idx - 1)
+		}
+		return m, nil
+	case "move_feed_down":
+		if m.feedSort != db.FeedSortManual {
+			m.statusMsg = "Feeds can only be moved in manual order"
+			return m, nil
+		}
+		idx := m.feedsList.Index()
+		if idx < len(m.feedsList.Items())-1 {
+			itemA := m.feedsList.Items()[idx].(feedItem)
+			itemB := m.feedsList.Items()[idx+1].(feedItem)
+			if isVirtualFeed(itemA.feed) || isVirtualFeed(itemB.feed) || itemA.feed.Folder != itemB.feed.Folder {
+				return m, nil
+			}
+			posA, posB := itemA.feed.Position, itemB.feed.Position
+			if posA == posB {
+				posA, posB = idx, idx+1
+			}
+			db.SwapFeedPositions(int(itemA.feed.ID), posA, int(itemB.feed.ID), posB)
+			return m, m.loadFeedsWithIndex(idx + 1)
+		}
+		return m, nil
+	case "delete_feed":
+		return m.deleteFeeds()
+	case "undo":
+		return m.undo()
+	case "toggle_pause":
+		return m.togglePause()
+	case "move_to_folder":
+		m.openCommandLine("folder ")
+		return m, nil
+
+	case "search_article":
+		m.openSearch()
+		return m, nil
+	case "search_next":
+		return m.nextMatch(max(count, 1))
+	case "search_prev":
+		return m.nextMatch(-max(count, 1))
+
+	case "toggle_select":
+		return m.toggleSelect()
+	case "select_range":
+		return m.selectRange()
+	case "select_all":
+		return m.selectAll()
+	case "toggle_read":
+		return m.toggleRead()
+	case "toggle_star":
+		return m.toggleStar()
+	case "export_articles":
+		return m, m.exportEntries
+
+	case "up", "down", "page_up", "page_down", "half_page_up", "half_page_down", "top", "bottom", "filter":
+		return m, m.navigate(action, count)
+	}
+
+	m.statusMsg = ErrorStyle.Render("Unknown action: " + action)
+	return m, nil
+}
+
+// navigate moves the cursor of the active pane. Moving in the feeds or
+// articles list loads what is under the cursor.
+func (m *Model) navigate(action string, count int) tea.Cmd {
+	if m.activePane == paneContent {
+		m.scrollContent(action, count)
+		return nil
+	}
+
+	l := &m.feedsList
+	if m.activePane == paneEntries {
+		l = &m.entriesList
+	}
+	if action == "filter" {
+		var cmd tea.Cmd
+		*l, cmd = l.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+		return cmd
+	}
+
+	before := l.Index()
+	moveList(l, action, count)
+	skipHeaders(l, l.Index() > before)
+	if l.Index() == before {
+		return nil
+	}
+	if m.activePane == paneEntries {
+		return tea.Batch(m.loadSelection(), m.loadMoreRiver())
+	}
+	return m.loadSelection()
+}
+
+func moveList(l *list.Model, action string, count int) {
+	if len(l.VisibleItems()) == 0 {
+		return
+	}
+	n := max(count, 1)
+	switch action {
+	case "up":
+		for range n {
+			l.CursorUp()
+		}
+	case "down":
+		for range n {
+			l.CursorDown()
+		}
+	case "page_up", "half_page_up", "page_down", "half_page_down":
+		step := l.Paginator.PerPage
+		if action == "half_page_up" || action == "half_page_down" {
+			step = max(step/2, 1)
+		}
+		idx := l.Index() + n*step
+		if action == "page_up" || action == "half_page_up" {
+			idx = l.Index() - n*step
+		}
+		l.Select(min(max(idx, 0), len(l.VisibleItems())-1))
+	case "top":
+		if count > 0 {
+			l.Select(min(count, len(l.VisibleItems())) - 1)
+		} else {
+			l.Select(0)
+		}
+	case "bottom":
+		if count > 0 {
+			l.Select(min(count, len(l.VisibleItems())) - 1)
+		} else {
+			l.Select(len(l.VisibleItems()) - 1)
+		}
+	}
+}
+
+func (m *Model) scrollContent(action string, count int) {
+	n := max(count, 1)
+	switch action {
+	case "up":
+		m.viewport.ScrollUp(n)
+	case "down":
+		m.viewport.ScrollDown(n)
+	case "page_up":
+		m.viewport.ScrollUp(n * m.viewport.Height)
+	case "page_down":
+		m.viewport.ScrollDown(n * m.viewport.Height)
+	case "half_page_up":
+		m.viewport.ScrollUp(n * m.viewport.Height / 2)
+	case "half_page_down":
+		m.viewport.ScrollDown(n * m.viewport.Height / 2)
+	case "top":
+		if count > 0 {
+			m.viewport.SetYOffset(count - 1)
+		} else {
+			m.viewport.GotoTop()
+		}
+	case "bottom":
+		if count > 0 {
+			m.viewport.SetYOffset(count - 1)
+		} else {
+			m.viewport.GotoBottom()
+		}
+	}
+}
+
+// loadSelection loads the entries of the selected feed, or the content of
+// the selected entry, depending on the active pane.
+func (m *Model) loadSelection() tea.Cmd {
+	if m.activePane == paneEntries {
+		return m.viewSelected()
+	}
+	if i, ok := m.feedsList.SelectedItem().(feedItem); ok {
+		m.currentFeed = i.feed
+		return m.loadEntries(i.feed)
+	}
+	return nil
+}
+
+// reloadContent renders the selected entry again, after something that
+// changes how articles look
+func (m *Model) reloadContent() tea.Cmd {
+	if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
+		return m.viewEntry(i.entry)
+	}
+	return nil
+}
+
+// clearFilter drops the filter applied to the active list, it reports
+// whether there was one
+func (m *Model) clearFilter() bool {
+	l := &m.feedsList
+	switch m.activePane {
+	case paneEntries:
+		l = &m.entriesList
+	case paneContent:
+		return false
+	}
+	if l.FilterState() != list.FilterApplied {
+		return false
+	}
+	l.ResetFilter()
+	return true
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package ui
+
+// cellSize returns the size of a terminal cell in pixels, or zeros when the
+// terminal doesn't report it
+func cellSize() (width, height int) {
+	return 0, 0
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// writeClipboard writes to the system clipboard
+var writeClipboard = clipboard.WriteAll
+
+// copyText puts text on the clipboard, reporting it as label, followed by
+// the text itself with show. OSC 52 asks the terminal to do it, which works
+// over SSH too, and the system clipboard covers terminals that ignore it.
+// Terminals don't tell whether they did, so copying is only reported as
+// done when the system clipboard took the text.
+func copyText(text, label string, show bool) tea.Cmd {
+	return func() tea.Msg {
+		seq := osc52.New(text)
+		if os.Getenv("TMUX") != "" {
+			seq = seq.Tmux()
+		} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+			seq = seq.Screen()
+		}
+		_, oscErr := seq.WriteTo(output)
+		shown := ""
+		if show {
+			shown = ": " + text
+		}
+		err := writeClipboard(text)
+		switch {
+		case err == nil:
+			return exportMsg("Copied " + label + shown)
+		case oscErr == nil:
+			return exportMsg("Sent " + label + " to the terminal clipboard (OSC 52)" + shown)
+		}
+		return exportMsg(ErrorStyle.Render("Copy failed: " + err.Error()))
+	}
+}
+
+// yank copies a part of the article shown: its "link", "title", "feed"
+// URL or its "markdown" body. The feed is the one of the selected entry,
+// a page opened from a link has none.
+func (m Model) yank(what string) (tea.Model, tea.Cmd) {
+	e, ok := m.shownEntry()
+	if what == "feed" {
+		i, _ := m.entriesList.SelectedItem().(entryItem)
+		e, ok = i.entry, true
+	}
+	if !ok {
+		m.statusMsg = "No article selected"
+		return m, nil
+	}
+	var text, label string
+	switch what {
+	case "link":
+		text, label = e.Link, "link"
+	case "title":
+		text, label = e.Title, "title"
+	case "feed":
+		text, label = m.currentFeed.URL, "feed URL"
+		if isVirtualFeed(m.currentFeed) {
+			// The river lists entries of every feed, use the entry's own
+			text = ""
+			for _, it := range m.feedsList.Items() {
+				if fi, ok := it.(feedItem); ok && fi.feed.ID == e.FeedID {
+					text = fi.feed.URL
+				}
+			}
+		}
+	case "markdown":
+		md, err := entryMarkdown(e)
+		if err != nil {
+			m.statusMsg = ErrorStyle.Render("Copy failed: " + err.Error())
+			return m, nil
+		}
+		text, label = md, "article as Markdown"
+	}
+	if text == "" {
+		m.statusMsg = "Nothing to copy, the " + label + " is empty"
+		return m, nil
+	}
+	return m, copyText(text, label, what != "markdown")
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCopyText(t *testing.T) {
+	tests := []struct {
+		name      string
+		clipboard error
+		show      bool
+		want      string
+	}{
+		{"system clipboard", nil, true, "Copied link: https://example.com/"},
+		{"terminal only", errors.New("no clipboard"), true, "Sent link to the terminal clipboard (OSC 52): https://example.com/"},
+		{"hidden text", nil, false, "Copied link"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			prevFile, prevClipboard := output.File, writeClipboard
+			t.Cleanup(func() { output.File, writeClipboard = prevFile, prevClipboard })
+			output.File = f
+			var copied string
+			writeClipboard = func(text string) error {
+				copied = text
+				return tt.clipboard
+			}
+			t.Setenv("TMUX", "")
+			t.Setenv("TERM", "xterm")
+
+			msg := copyText("https://example.com/", "link", tt.show)()
+			if got := string(msg.(exportMsg)); got != tt.want {
+				t.Errorf("status %q, want %q", got, tt.want)
+			}
+			if copied != "https://example.com/" {
+				t.Errorf("system clipboard got %q", copied)
+			}
+			// The sequence goes through the program output
+			written, _ := os.ReadFile(f.Name())
+			if !strings.HasPrefix(string(written), "\x1b]52;c;") {
+				t.Errorf("output %q, want an OSC 52 sequence", written)
+			}
+		})
+	}
+}
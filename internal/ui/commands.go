@@ -0,0 +1,584 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const maxCommandHistory = 100
+
+// command is something that can be run from the ":" command line. Every
+// keymap action is also a command, taking an optional count.
+type command struct {
+	name     string
+	usage    string
+	complete func(m Model, arg string) []string
+	run      func(m Model, args []string) (tea.Model, tea.Cmd)
+	// raw commands get the rest of the line as it was typed, in one
+	// argument, for shell commands that do their own quoting
+	raw bool
+}
+
+// commandTable lists the commands, the keymap actions first. Rebinding
+// keys doesn't change the actions, so it is built once.
+var commandTable []command
+
+func init() {
+	commandTable = newCommandTable()
+}
+
+// newCommandTable builds the command table. A command can take the name of
+// an action to run it with arguments.
+func newCommandTable() []command {
+	extra := []command{
+		{
+			name:     "feed",
+			usage:    "<title>",
+			complete: completeFeedTitles,
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) == 0 {
+					return m.commandError("feed: missing title")
+				}
+				return m.jumpToFeed(strings.Join(args, " "))
+			},
+		},
+		{
+			name:  "add",
+			usage: "<url>",
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) != 1 {
+					return m.commandError("add: expected one URL")
+				}
+				m.loading = true
+				return m, m.addFeed(args[0])
+			},
+		},
+		{
+			name:     "import",
+			usage:    "<path>",
+			complete: completePath,
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) != 1 {
+					return m.commandError("import: expected one path")
+				}
+				m.loading = true
+				return m, m.importOPML(config.ExpandHome(args[0]))
+			},
+		},
+		{
+			name:     "export",
+			usage:    "[path]",
+			complete: completePath,
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) == 0 {
+					return m, m.exportOPML
+				}
+				return m, m.exportOPMLTo(config.ExpandHome(args[0]))
+			},
+		},
+		{
+			name:     "export_articles",
+			usage:    "[path]",
+			complete: completePath,
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) == 0 {
+					return m, m.exportEntries
+				}
+				return m, m.exportEntriesTo(config.ExpandHome(args[0]))
+			},
+		},
+		{
+			name:     "folder",
+			usage:    "[name]",
+			complete: completeFolders,
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				return m.moveToFolder(strings.Join(args, " "))
+			},
+		},
+		{
+			name:  "mark_all_read",
+			usage: "",
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				m.askConfirm("Mark every article of every feed as read?", func(m Model) (tea.Model, tea.Cmd) {
+					return m, m.markAllRead(0)
+				})
+				return m, nil
+			},
+		},
+		{
+			name:  "mark_feed_read",
+			usage: "",
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				switch {
+				case m.currentFeed.ID == 0:
+					return m.commandError("mark_feed_read: no feed selected")
+				case isVirtualFeed(m.currentFeed):
+					return m.commandError("mark_feed_read: %s isn't a feed, use mark_all_read", m.currentFeed.Title)
+				}
+				return m, m.markAllRead(m.currentFeed.ID)
+			},
+		},
+		{
+			name:     "restore",
+			usage:    "<title>",
+			complete: completeDeletedFeeds,
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) == 0 {
+					return m.commandError("restore: missing title")
+				}
+				return m.restoreFeed(strings.Join(args, " "))
+			},
+		},
+		{
+			name:  "empty_trash",
+			usage: "",
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				return m.emptyTrash()
+			},
+		},
+		{
+			name:  "sort",
+			usage: "<order>",
+			complete: func(m Model, arg string) []string {
+				return filterPrefix(m.sortNames(), arg)
+			},
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) != 1 {
+					return m.commandError("sort: expected one order")
+				}
+				return m.setSort(args[0])
+			},
+		},
+		{
+			name:  "date_range",
+			usage: "<all|today|week|from [to]>",
+			complete: func(m Model, arg string) []string {
+				return filterPrefix([]string{"all", "today", "week", m.dates.now().Format(db.DateLayout)}, arg)
+			},
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				return m.setDateRange(args)
+			},
+		},
+		{
+			name:  "reset_layout",
+			usage: "",
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				return m.resetLayout()
+			},
+		},
+		{
+			name:  "layout",
+			usage: "<mode>",
+			complete: func(m Model, arg string) []string {
+				return filterPrefix(config.LayoutModes, arg)
+			},
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) != 1 {
+					return m.commandError("layout: expected one mode")
+				}
+				return m.setLayout(args[0])
+			},
+		},
+		{
+			name:  "pipe",
+			usage: "<name> | [format] <shell command>",
+			complete: func(m Model, arg string) []string {
+				names := slices.Clone(config.PipeFormats)
+				for _, p := range m.cfg.Pipes {
+					names = append(names, p.Name)
+				}
+				return filterPrefix(names, arg)
+			},
+			raw: true,
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) == 0 {
+					return m.commandError("pipe: missing command")
+				}
+				return m.pipe(args[0])
+			},
+		},
+		{
+			name:  "theme",
+			usage: "<name>",
+			complete: func(m Model, arg string) []string {
+				return filterPrefix(append([]string{"auto"}, themeNames(m.cfg)...), arg)
+			},
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				if len(args) != 1 {
+					return m.commandError("theme: expected one name")
+				}
+				t, err := resolveTheme(args[0], m.cfg, m.darkBackground)
+				if err != nil {
+					return m.commandError("theme: %v", err)
+				}
+				m.setTheme(t)
+				return m, m.rerender()
+			},
+		},
+	}
+
+	var cmds []command
+	for _, b := range defaultKeyMap().bindings {
+		action := b.action
+		if slices.ContainsFunc(extra, func(c command) bool { return c.name == action }) {
+			continue
+		}
+		cmds = append(cmds, command{
+			name:  action,
+			usage: "[count]",
+			run: func(m Model, args []string) (tea.Model, tea.Cmd) {
+				count := 0
+				if len(args) > 0 {
+					n, err := strconv.Atoi(args[0])
+					if err != nil || n < 0 {
+						return m.commandError("%s: count must be a number", action)
+					}
+					count = n
+				}
+				return m.runAction(action, count)
+			},
+		})
+	}
+
+	return append(cmds, extra...)
+}
+
+func (m Model) command(name string) (command, bool) {
+	for _, c := range commandTable {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func (m Model) commandError(format string, args ...any) (tea.Model, tea.Cmd) {
+	m.statusMsg = ErrorStyle.Render(fmt.Sprintf(format, args...))
+	return m, nil
+}
+
+// openCommandLine switches to the ":" prompt, optionally pre-filled
+func (m *Model) openCommandLine(text string) {
+	m.state = stateCommand
+	m.commandInput.SetValue(text)
+	m.commandInput.CursorEnd()
+	m.commandInput.Focus()
+	m.historyIdx = len(m.commandHistory)
+	m.completions = nil
+}
+
+func (m Model) updateCommandLine(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.state = stateMain
+		m.completions = nil
+		return m, nil
+	case "enter":
+		line := strings.TrimSpace(m.commandInput.Value())
+		m.state = stateMain
+		m.completions = nil
+		if line == "" {
+			return m, nil
+		}
+		m.commandHistory = appendHistory(m.commandHistory, line)
+		next, cmd := m.runCommandLine(line)
+		return next, tea.Batch(cmd, saveCommandHistory(m.commandHistory))
+	case "tab", "shift+tab":
+		m.complete(msg.String() == "shift+tab")
+		return m, nil
+	case "up":
+		if m.historyIdx > 0 {
+			m.historyIdx--
+			m.commandInput.SetValue(m.commandHistory[m.historyIdx])
+			m.commandInput.CursorEnd()
+		}
+		return m, nil
+	case "down":
+		if m.historyIdx < len(m.commandHistory) {
+			m.historyIdx++
+			value := ""
+			if m.historyIdx < len(m.commandHistory) {
+				value = m.commandHistory[m.historyIdx]
+			}
+			m.commandInput.SetValue(value)
+			m.commandInput.CursorEnd()
+		}
+		return m, nil
+	case "backspace":
+		// Backspace on an empty line leaves, like in vim
+		if m.commandInput.Value() == "" {
+			m.state = stateMain
+			return m, nil
+		}
+	}
+	m.completions = nil
+	m.commandInput, cmd = m.commandInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) runCommandLine(line string) (tea.Model, tea.Cmd) {
+	args := splitArgs(line)
+	if len(args) == 0 {
+		return m, nil
+	}
+	c, ok := m.command(args[0])
+	if !ok {
+		return m.commandError("Unknown command: %s", args[0])
+	}
+	if c.raw {
+		_, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if rest = strings.TrimSpace(rest); rest == "" {
+			return c.run(m, nil)
+		}
+		return c.run(m, []string{rest})
+	}
+	return c.run(m, args[1:])
+}
+
+// complete cycles through the completions of the word under the cursor,
+// the command name or its argument.
+func (m *Model) complete(backwards bool) {
+	if m.completions == nil {
+		value := m.commandInput.Value()
+		name, arg, hasArg := strings.Cut(value, " ")
+		var candidates []string
+		if !hasArg {
+			var names []string
+			for _, c := range commandTable {
+				names = append(names, c.name)
+			}
+			candidates = filterPrefix(names, name)
+			m.completionBase = ""
+		} else if c, ok := m.command(name); ok && c.complete != nil {
+			candidates = c.complete(*m, strings.TrimLeft(arg, " "))
+			m.completionBase = name + " "
+		}
+		if len(candidates) == 0 {
+			return
+		}
+		m.completions = candidates
+		m.completionIdx = -1
+	}
+
+	if backwards {
+		m.completionIdx = (m.completionIdx - 1 + len(m.completions)) % len(m.completions)
+	} else {
+		m.completionIdx = (m.completionIdx + 1) % len(m.completions)
+	}
+	m.commandInput.SetValue(m.completionBase + m.completions[m.completionIdx])
+	m.commandInput.CursorEnd()
+	if m.completionBase == "" && len(m.completions) == 1 {
+		// A unique command name is done, the next tab completes its argument
+		m.commandInput.SetValue(m.completions[0] + " ")
+		m.commandInput.CursorEnd()
+		m.completions = nil
+	}
+}
+
+// commandLineView renders the prompt in place of the status bar, with the
+// completions after it
+func (m Model) commandLineView(width int) string {
+	line := m.commandInput.View()
+	if len(m.completions) > 1 {
+		var parts []string
+		for i, c := range m.completions {
+			if i == m.completionIdx {
+				parts = append(parts, StatusPillStyle.Render(c))
+			} else {
+				parts = append(parts, HelpStyle.Render(c))
+			}
+		}
+		line += "  " + strings.Join(parts, " ")
+	}
+	return lipgloss.NewStyle().MaxWidth(width).Render(line)
+}
+
+// jumpToFeed selects the first feed whose title matches query, trying an
+// exact match, then a prefix, then a substring.
+func (m Model) jumpToFeed(query string) (tea.Model, tea.Cmd) {
+	q := strings.ToLower(query)
+	matchers := []func(string) bool{
+		func(t string) bool { return t == q },
+		func(t string) bool { return strings.HasPrefix(t, q) },
+		func(t string) bool { return strings.Contains(t, q) },
+	}
+	items := m.feedsList.Items()
+	for _, match := range matchers {
+		for idx, it := range items {
+			fi, ok := it.(feedItem)
+			if !ok || !match(strings.ToLower(fi.feed.Title)) {
+				continue
+			}
+			if m.feedsList.FilterState() != list.Unfiltered {
+				m.feedsList.ResetFilter()
+			}
+			m.feedsList.Select(idx)
+			m.activePane = paneFeeds
+			m.currentFeed = fi.feed
+			return m, m.loadEntries(fi.feed)
+		}
+	}
+	return m.commandError("No feed matches %q", query)
+}
+
+// markAllRead marks the entries of a feed read, or of every feed when
+// feedID is 0, and can be undone
+func (m Model) markAllRead(feedID int64) tea.Cmd {
+	undoKey := m.keys.binding("undo").Help().Key
+	return func() tea.Msg {
+		ids, err := db.MarkAllAsRead(feedID)
+		if err != nil {
+			return errMsg(err)
+		}
+		if len(ids) == 0 {
+			return selectionMsg{status: "No unread articles"}
+		}
+		marked := "Marked " + plural(len(ids), "article") + " read"
+		return selectionMsg{
+			status:      marked + ", press " + undoKey + " to undo",
+			reloadFeeds: true,
+			readIDs:     ids,
+			read:        true,
+			undo: &undoStep{
+				description: "marking " + plural(len(ids), "article") + " read",
+				undo:        setRead(ids, false, "Marked "+plural(len(ids), "article")+" unread again"),
+			},
+		}
+	}
+}
+
+func completeFeedTitles(m Model, arg string) []string {
+	var titles []string
+	a := strings.ToLower(arg)
+	for _, it := range m.feedsList.Items() {
+		if fi, ok := it.(feedItem); ok && strings.Contains(strings.ToLower(fi.feed.Title), a) {
+			titles = append(titles, fi.feed.Title)
+		}
+	}
+	return titles
+}
+
+// completePath completes file names, keeping a leading ~ as typed
+func completePath(_ Model, arg string) []string {
+	dir, prefix := filepath.Split(arg)
+	readDir := config.ExpandHome(dir)
+	if readDir == "" {
+		readDir = "."
+	}
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		if e.IsDir() {
+			name += string(filepath.Separator)
+		}
+		out = append(out, dir+name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// splitArgs splits a command line on spaces, keeping quoted strings
+// together.
+func splitArgs(line string) []string {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg := false
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			cur.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args
+}
+
+func appendHistory(history []string, line string) []string {
+	// Running a line again moves it to the end instead of duplicating it
+	for i, h := range history {
+		if h == line {
+			history = append(history[:i:i], history[i+1:]...)
+			break
+		}
+	}
+	history = append(history, line)
+	if len(history) > maxCommandHistory {
+		history = history[len(history)-maxCommandHistory:]
+	}
+	return history
+}
+
+type commandHistoryMsg []string
+
+func loadCommandHistory() tea.Msg {
+	value, err := db.GetSetting("command_history", "[]")
+	if err != nil {
+		return errMsg(err)
+	}
+	var history []string
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		return nil
+	}
+	return commandHistoryMsg(history)
+}
+
+func saveCommandHistory(history []string) tea.Cmd {
+	return func() tea.Msg {
+		data, err := json.Marshal(history)
+		if err != nil {
+			return errMsg(err)
+		}
+		if err := db.SetSetting("command_history", string(data)); err != nil {
+			return errMsg(err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,28 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"sort newest", []string{"sort", "newest"}},
+		{"  feed \t Go   blog ", []string{"feed", "Go", "blog"}},
+		{`export "~/My Files/feeds.opml"`, []string{"export", "~/My Files/feeds.opml"}},
+		{`folder 'News & "stuff"'`, []string{"folder", `News & "stuff"`}},
+		{`a"b c"d`, []string{"ab cd"}},
+		{`theme ""`, []string{"theme", ""}},
+		{`open "unterminated quote`, []string{"open", "unterminated quote"}},
+	}
+	for _, tt := range tests {
+		if got := splitArgs(tt.line); !slices.Equal(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const maxUndo = 20
+
+// confirmDialog asks before running a destructive action
+type confirmDialog struct {
+	prompt string
+	yes    bool // the confirm button has the focus
+	onYes  func(m Model) (tea.Model, tea.Cmd)
+}
+
+// undoStep reverts an action, see pushUndo
+type undoStep struct {
+	description string
+	undo        tea.Cmd
+	// restores feeds from the trash, which emptying it makes impossible
+	trash bool
+}
+
+// askConfirm opens the dialog, onYes runs if the user confirms. The focus
+// starts on cancel so a stray enter does nothing.
+func (m *Model) askConfirm(prompt string, onYes func(m Model) (tea.Model, tea.Cmd)) {
+	m.confirm = confirmDialog{prompt: prompt, onYes: onYes}
+	m.state = stateConfirm
+}
+
+func (m Model) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y":
+		m.state = stateMain
+		return m.confirm.onYes(m)
+	case "n", "N", "q", "esc", "ctrl+c":
+		m.state = stateMain
+	case "tab", "shift+tab", "left", "right", "h", "l":
+		m.confirm.yes = !m.confirm.yes
+	case "enter":
+		m.state = stateMain
+		if m.confirm.yes {
+			return m.confirm.onYes(m)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) confirmView() string {
+	button := func(label string, focused bool) string {
+		if focused {
+			return PaneTitleStyle.Render(label)
+		}
+		return StatusTextStyle.Render(label)
+	}
+	buttons := lipgloss.JoinHorizontal(lipgloss.Top,
+		button("Yes (y)", m.confirm.yes), "  ", button("No (n)", !m.confirm.yes))
+	width := min(max(lipgloss.Width(m.confirm.prompt), lipgloss.Width(buttons)), max(m.width-8, 20))
+	body := lipgloss.JoinVertical(lipgloss.Center,
+		lipgloss.NewStyle().Width(width).Align(lipgloss.Center).Render(m.confirm.prompt),
+		"",
+		buttons,
+	)
+	box := ActivePaneStyle.Copy().Padding(1, 2).Render(body)
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
+
+// pushUndo remembers how to revert the action that was just run
+func (m *Model) pushUndo(step undoStep) {
+	m.undoStack = append(m.undoStack, step)
+	if len(m.undoStack) > maxUndo {
+		m.undoStack = m.undoStack[len(m.undoStack)-maxUndo:]
+	}
+}
+
+func (m Model) undo() (tea.Model, tea.Cmd) {
+	if len(m.undoStack) == 0 {
+		m.statusMsg = "Nothing to undo"
+		return m, nil
+	}
+	step := m.undoStack[len(m.undoStack)-1]
+	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	m.statusMsg = "Undo: " + step.description
+	return m, step.undo
+}
+
+func (m Model) deleteFeeds() (tea.Model, tea.Cmd) {
+	ids := m.selectedFeedIDs()
+	if len(ids) == 0 {
+		return m, nil
+	}
+	what := plural(len(ids), "feed")
+	if len(ids) == 1 {
+		if fi, ok := m.feedsList.SelectedItem().(feedItem); ok && fi.feed.ID == ids[0] {
+			what = fmt.Sprintf("%q", fi.feed.Title)
+		}
+	}
+	m.askConfirm(fmt.Sprintf("Delete %s?\n%s", what, m.trashNote(len(ids))), func(m Model) (tea.Model, tea.Cmd) {
+		m.clearSelection()
+		deleted := "Deleted " + plural(len(ids), "feed")
+		undoKey := m.keys.binding("undo").Help().Key
+		m.pushUndo(undoStep{
+			description: "deleting " + plural(len(ids), "feed"),
+			undo: func() tea.Msg {
+				if err := db.RestoreFeeds(ids); err != nil {
+					return errMsg(err)
+				}
+				return selectionMsg{status: "Restored " + plural(len(ids), "feed"), reloadFeeds: true}
+			},
+			trash: true,
+		})
+		return m, func() tea.Msg {
+			for _, id := range ids {
+				if err := db.DeleteFeed(id); err != nil {
+					return errMsg(err)
+				}
+			}
+			return selectionMsg{status: deleted + ", press " + undoKey + " to undo", reloadFeeds: true}
+		}
+	})
+	return m, nil
+}
+
+// trashNote tells how long n deleted feeds can be restored
+func (m Model) trashNote(n int) string {
+	subject := "It"
+	if n > 1 {
+		subject = "They"
+	}
+	keep := m.cfg.Trash.Keep
+	switch {
+	case keep <= 0:
+		return subject + " can be restored until the next start."
+	case keep%(24*time.Hour) == 0:
+		return fmt.Sprintf("%s can be restored for %s.", subject, plural(int(keep/(24*time.Hour)), "day"))
+	}
+	return fmt.Sprintf("%s can be restored for %s.", subject, keep)
+}
+
+func (m Model) purgeTrash() tea.Msg {
+	if err := db.PurgeDeletedFeeds(m.cfg.Trash.Keep); err != nil {
+		return errMsg(err)
+	}
+	return nil
+}
+
+func (m Model) restoreFeed(title string) (tea.Model, tea.Cmd) {
+	feeds, err := db.GetDeletedFeeds()
+	if err != nil {
+		return m.commandError("restore: %v", err)
+	}
+	for _, f := range feeds {
+		if f.Title == title || f.URL == title {
+			return m, func() tea.Msg {
+				if err := db.RestoreFeeds([]int64{f.ID}); err != nil {
+					return errMsg(err)
+				}
+				return selectionMsg{status: fmt.Sprintf("Restored %q", f.Title), reloadFeeds: true}
+			}
+		}
+	}
+	return m.commandError("restore: no deleted feed named %q", title)
+}
+
+func completeDeletedFeeds(m Model, arg string) []string {
+	feeds, err := db.GetDeletedFeeds()
+	if err != nil {
+		return nil
+	}
+	var titles []string
+	a := strings.ToLower(arg)
+	for _, f := range feeds {
+		if strings.Contains(strings.ToLower(f.Title), a) {
+			titles = append(titles, f.Title)
+		}
+	}
+	return titles
+}
+
+func (m Model) emptyTrash() (tea.Model, tea.Cmd) {
+	m.askConfirm("Remove every deleted feed for good?\nThis can't be undone.", func(m Model) (tea.Model, tea.Cmd) {
+		// Restoring deleted feeds is no longer possible
+		m.undoStack = slices.DeleteFunc(m.undoStack, func(s undoStep) bool { return s.trash })
+		return m, func() tea.Msg {
+			if err := db.PurgeDeletedFeeds(0); err != nil {
+				return errMsg(err)
+			}
+			return exportMsg("Trash emptied")
+		}
+	})
+	return m, nil
+}
@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/jeremiev/lazyrss/internal/db"
+)
+
+func TestMarkAllReadUndo(t *testing.T) {
+	m := newTestModel(t)
+	before, _ := db.CountUnread()
+
+	m = runCmd(m, m.markAllRead(0))
+	if n, _ := db.CountUnread(); n != 0 {
+		t.Fatalf("%d unread after marking all read", n)
+	}
+	if slices.Contains(listedRead(m), false) {
+		t.Errorf("list shows unread entries: %v", listedRead(m))
+	}
+
+	tm, cmd := m.undo()
+	m = runCmd(tm.(Model), cmd)
+	if n, _ := db.CountUnread(); n != before {
+		t.Errorf("%d unread after undoing, want %d", n, before)
+	}
+	if slices.Contains(listedRead(m), true) {
+		t.Errorf("list shows read entries: %v", listedRead(m))
+	}
+	if len(m.undoStack) != 0 {
+		t.Errorf("undo stack %v, want it empty", m.undoStack)
+	}
+}
+
+func TestToggleReadUndo(t *testing.T) {
+	m := newTestModel(t)
+	m.entriesList.Select(1)
+	id := m.entriesList.SelectedItem().(entryItem).entry.ID
+
+	tm, cmd := m.toggleRead()
+	m = runCmd(tm.(Model), cmd)
+	if read := entryRead(t, m.currentFeed.ID, id); !read {
+		t.Fatal("entry not marked read")
+	}
+
+	tm, cmd = m.undo()
+	m = runCmd(tm.(Model), cmd)
+	if read := entryRead(t, m.currentFeed.ID, id); read {
+		t.Error("entry still read after undoing")
+	}
+	if listedRead(m)[1] {
+		t.Error("list still shows the entry read")
+	}
+}
+
+func TestEmptyTrashKeepsOtherUndo(t *testing.T) {
+	m := newTestModel(t)
+	m.pushUndo(undoStep{description: "marking 1 article read"})
+	m.pushUndo(undoStep{description: "deleting 1 feed", trash: true})
+
+	tm, _ := m.emptyTrash()
+	m = tm.(Model)
+	tm, _ = m.confirm.onYes(m)
+	m = tm.(Model)
+	if len(m.undoStack) != 1 || m.undoStack[0].trash {
+		t.Errorf("undo stack %v, want only the read change", m.undoStack)
+	}
+}
+
+func entryRead(t *testing.T, feedID, id int64) bool {
+	t.Helper()
+	entries, err := db.GetEntries(db.EntryQuery{FeedID: feedID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e.Read
+		}
+	}
+	t.Fatalf("no entry %d", id)
+	return false
+}
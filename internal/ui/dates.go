@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// dateFormat shows the publication dates of entries the way the config
+// says. Dates are stored in UTC.
+type dateFormat struct {
+	list     string
+	article  string
+	relative bool
+	loc      *time.Location
+}
+
+func newDateFormat(cfg config.Dates) dateFormat {
+	return dateFormat{list: cfg.ListFormat, article: cfg.ArticleFormat, relative: cfg.Relative, loc: cfg.Location()}
+}
+
+// now is the current time in the time zone dates are shown in
+func (d dateFormat) now() time.Time {
+	return time.Now().In(d.loc)
+}
+
+// listDate is the date in front of an entry in the articles pane
+func (d dateFormat) listDate(t, now time.Time) string {
+	t = t.In(d.loc)
+	var s string
+	if rel := relativeDate(t, now); d.relative && rel != "" {
+		s = rel
+	} else if d.list != "" {
+		s = t.Format(d.list)
+	} else if t.Year() == now.Year() {
+		// Same year: "19 Feb" or " 9 Feb", padded to line up
+		s = fmt.Sprintf("%6s", t.Format("2 Jan"))
+	} else {
+		// Different year: "  2025"
+		s = fmt.Sprintf("%6s", t.Format("2006"))
+	}
+	if d.relative {
+		// As wide as "yesterday"
+		s = fmt.Sprintf("%9s", s)
+	}
+	return s
+}
+
+// articleDate is the date above an article, followed by how long ago it
+// was for recent ones
+func (d dateFormat) articleDate(t, now time.Time) string {
+	t = t.In(d.loc)
+	s := t.Format(d.article)
+	if rel := relativeDate(t, now); d.relative && rel != "" {
+		s += " (" + rel + ")"
+	}
+	return s
+}
+
+// relativeDate tells how long ago t was within the last week, or returns
+// an empty string
+func relativeDate(t, now time.Time) string {
+	age := now.Sub(t)
+	switch days := daysBetween(t, now); {
+	case age < 0:
+		return ""
+	case age < time.Minute:
+		return "now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age/time.Minute))
+	case days == 0:
+		return fmt.Sprintf("%dh ago", int(age/time.Hour))
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return fmt.Sprintf("%dd ago", days)
+	}
+	return ""
+}
+
+// daysBetween counts the midnights from t to now, in the time zone of now
+func daysBetween(t, now time.Time) int {
+	t = t.In(now.Location())
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, now.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// Rounded, days aren't all 24h long across DST changes
+	return int((today.Sub(day) + 12*time.Hour) / (24 * time.Hour))
+}
+
+// dayHeader is a line of the articles pane above the entries of a day, or
+// of an older period. The cursor skips it.
+type dayHeader string
+
+func (h dayHeader) Title() string       { return LabelStyle.Render(string(h)) }
+func (h dayHeader) Description() string { return "" }
+func (h dayHeader) FilterValue() string { return "" }
+
+// dayGroup names the period the date t is in: today, yesterday, this week
+// (starting on Monday like the date range), or its month
+func dayGroup(t, now time.Time) string {
+	if t.IsZero() {
+		return "Undated"
+	}
+	t = t.In(now.Location())
+	days := daysBetween(t, now)
+	switch {
+	case days <= 0:
+		return "Today"
+	case days == 1:
+		return "Yesterday"
+	case days <= (int(now.Weekday())+6)%7:
+		return "This week"
+	case t.Year() == now.Year():
+		return t.Format("January")
+	}
+	return t.Format("January 2006")
+}
+
+// groupedByDay reports whether the entries listed get day headers: when
+// the config asks for them and they are sorted by date
+func (m Model) groupedByDay(feedID int64, sort db.EntrySort) bool {
+	if !m.cfg.Dates.GroupByDay || feedID == recentFeedID {
+		return false
+	}
+	return sort == db.EntrySortNewest || sort == db.EntrySortOldest
+}
+
+// skipHeaders moves the cursor off a day header, on in the direction it
+// was moving, or back at the end of the list
+func skipHeaders(l *list.Model, forward bool) {
+	items := l.VisibleItems()
+	step := 1
+	if !forward {
+		step = -1
+	}
+	for _, dir := range []int{step, -step} {
+		for i := l.Index(); i >= 0 && i < len(items); i += dir {
+			if _, ok := items[i].(dayHeader); !ok {
+				l.Select(i)
+				return
+			}
+		}
+	}
+}
+
+// entryItems lists the entries of msg, under day headers when they are
+// grouped by day
+func (m Model) entryItems(msg entriesMsg) []list.Item {
+	grouped := m.groupedByDay(msg.feedID, msg.prefs.EntrySort)
+	now := m.dates.now()
+	group := ""
+	if msg.appendPage {
+		// Carry on under the header of the last entry listed
+		items := m.entriesList.Items()
+		for i := len(items) - 1; i >= 0 && group == ""; i-- {
+			if e, ok := items[i].(entryItem); ok {
+				group = dayGroup(e.entry.PublishedAt, now)
+			}
+		}
+	}
+	items := make([]list.Item, 0, len(msg.entries))
+	for _, e := range msg.entries {
+		if g := dayGroup(e.PublishedAt, now); grouped && g != group {
+			items = append(items, dayHeader(g))
+			group = g
+		}
+		items = append(items, entryItem{entry: e, showDates: m.showEntryDates, dates: m.dates, selection: m.selectedEntryIDs})
+	}
+	return items
+}
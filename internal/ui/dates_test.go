@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeDate(t *testing.T) {
+	// A Wednesday evening
+	now := time.Date(2024, 3, 13, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{-time.Hour, ""}, // in the future
+		{30 * time.Second, "now"},
+		{25 * time.Minute, "25m ago"},
+		{3 * time.Hour, "3h ago"},
+		{20 * time.Hour, "20h ago"},   // still today
+		{21 * time.Hour, "yesterday"}, // 23:00 the day before
+		{44 * time.Hour, "yesterday"},
+		{45 * time.Hour, "2d ago"},
+		{6 * 24 * time.Hour, "6d ago"},
+		{7 * 24 * time.Hour, ""},
+	}
+	for _, tt := range tests {
+		if got := relativeDate(now.Add(-tt.ago), now); got != tt.want {
+			t.Errorf("relativeDate(%v ago) = %q, want %q", tt.ago, got, tt.want)
+		}
+	}
+}
+
+func TestDaysBetween(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+	tests := []struct {
+		t, now time.Time
+		want   int
+	}{
+		{time.Date(2024, 3, 13, 0, 0, 0, 0, paris), time.Date(2024, 3, 13, 23, 59, 0, 0, paris), 0},
+		{time.Date(2024, 3, 12, 23, 59, 0, 0, paris), time.Date(2024, 3, 13, 0, 1, 0, 0, paris), 1},
+		// The night clocks go forward is 23 hours long
+		{time.Date(2024, 3, 30, 12, 0, 0, 0, paris), time.Date(2024, 4, 1, 12, 0, 0, 0, paris), 2},
+		// Counted in the time zone of now, not of t
+		{time.Date(2024, 3, 13, 23, 30, 0, 0, time.UTC), time.Date(2024, 3, 14, 9, 0, 0, 0, paris), 0},
+	}
+	for _, tt := range tests {
+		if got := daysBetween(tt.t, tt.now); got != tt.want {
+			t.Errorf("daysBetween(%v, %v) = %d, want %d", tt.t, tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestDayGroup(t *testing.T) {
+	// A Wednesday, the week started on Monday the 11th
+	now := time.Date(2024, 3, 13, 20, 0, 0, 0, time.UTC)
+	day := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 10, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Time{}, "Undated"},
+		{day(3, 13), "Today"},
+		{day(3, 14), "Today"}, // in the future
+		{day(3, 12), "Yesterday"},
+		{day(3, 11), "This week"},
+		{day(3, 10), "March"},
+		{day(1, 2), "January"},
+		{time.Date(2023, 12, 31, 10, 0, 0, 0, time.UTC), "December 2023"},
+	}
+	for _, tt := range tests {
+		if got := dayGroup(tt.t, now); got != tt.want {
+			t.Errorf("dayGroup(%v) = %q, want %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestListDate(t *testing.T) {
+	now := time.Date(2024, 3, 13, 20, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		d    dateFormat
+		t    time.Time
+		want string
+	}{
+		{"this year", dateFormat{loc: time.UTC}, time.Date(2024, 2, 19, 0, 0, 0, 0, time.UTC), "19 Feb"},
+		{"padded day", dateFormat{loc: time.UTC}, time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC), " 9 Feb"},
+		{"other year", dateFormat{loc: time.UTC}, time.Date(2023, 2, 9, 0, 0, 0, 0, time.UTC), "  2023"},
+		{"format", dateFormat{list: "2006-01-02", loc: time.UTC}, time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC), "2024-02-09"},
+		{"relative", dateFormat{relative: true, loc: time.UTC}, now.Add(-3 * time.Hour), "   3h ago"},
+		{"relative too old", dateFormat{relative: true, loc: time.UTC}, time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC), "    9 Feb"},
+		{"time zone", dateFormat{loc: time.FixedZone("UTC+3", 3*3600)}, time.Date(2024, 2, 9, 22, 0, 0, 0, time.UTC), "10 Feb"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.listDate(tt.t, now); got != tt.want {
+			t.Errorf("%s: listDate = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// entryMarkdown converts the body of an entry to Markdown, its content or
+// else its description. Relative links point to the article's site.
+func entryMarkdown(e db.Entry) (string, error) {
+	html := e.Content
+	if html == "" {
+		html = e.Description
+	}
+	var opts []converter.ConvertOptionFunc
+	if e.Link != "" {
+		opts = append(opts, converter.WithDomain(e.Link))
+	}
+	return htmltomarkdown.ConvertString(html, opts...)
+}
+
+// openInPager shows the article as rendered, in plain text with its links
+// listed at the end, in $PAGER
+func (m Model) openInPager() (tea.Model, tea.Cmd) {
+	if m.content == "" {
+		m.statusMsg = "No article selected"
+		return m, nil
+	}
+	text := ansi.Strip(m.articleText(true, true, nil))
+	return m.openExternal(envCommand("PAGER", "less"), "lazyrss-*.txt", text)
+}
+
+// shownEntry returns the article shown as an entry: the page opened from a
+// link when there is one, or else the selected entry
+func (m Model) shownEntry() (db.Entry, bool) {
+	if m.pageURL != "" {
+		return db.Entry{Title: m.pageTitle, Link: m.pageURL, Content: m.pageHTML}, true
+	}
+	i, ok := m.entriesList.SelectedItem().(entryItem)
+	return i.entry, ok
+}
+
+// openInEditor opens the article as Markdown in $VISUAL or $EDITOR. Changes
+// aren't read back, the file is removed when the editor exits.
+func (m Model) openInEditor() (tea.Model, tea.Cmd) {
+	e, ok := m.shownEntry()
+	if !ok {
+		m.statusMsg = "No article selected"
+		return m, nil
+	}
+	md, err := entryMarkdown(e)
+	if err != nil {
+		m.statusMsg = ErrorStyle.Render("Editor: " + err.Error())
+		return m, nil
+	}
+	text := "# " + e.Title + "\n\n"
+	if e.Link != "" {
+		text += "<" + e.Link + ">\n\n"
+	}
+	text += md + "\n"
+	editor := envCommand("VISUAL", "")
+	if editor == "" {
+		editor = envCommand("EDITOR", "vi")
+	}
+	return m.openExternal(editor, "lazyrss-*.md", text)
+}
+
+// envCommand returns the command in an environment variable, or fallback
+// when it is unset
+func envCommand(name, fallback string) string {
+	if c := strings.TrimSpace(os.Getenv(name)); c != "" {
+		return c
+	}
+	return fallback
+}
+
+// openExternal writes text to a temporary file and runs command on it,
+// suspending the TUI until it exits. Like link handlers, the command isn't
+// run by a shell but may quote arguments.
+func (m Model) openExternal(command, pattern, text string) (tea.Model, tea.Cmd) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		m.statusMsg = ErrorStyle.Render("Temporary file: " + err.Error())
+		return m, nil
+	}
+	path := f.Name()
+	_, err = f.WriteString(text)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		m.statusMsg = ErrorStyle.Render("Temporary file: " + err.Error())
+		return m, nil
+	}
+
+	args := append(splitArgs(command), path)
+	return m, execTerminal(exec.Command(args[0], args[1:]...), func(err error) tea.Msg {
+		os.Remove(path)
+		if err != nil {
+			return exportMsg(ErrorStyle.Render(fmt.Sprintf("%s: %v", args[0], err)))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// A page read from a link replaces the entry for the programs the article
+// is sent to
+func TestShownPage(t *testing.T) {
+	m := newTestModel(t)
+	entry, _ := m.shownEntry()
+	page := m.renderPage("Linked page", "http://linked.example/page", "Text of the *page*", "<p>Text of the <em>page</em></p>")
+
+	tests := []struct {
+		name  string
+		shown bool
+		want  string
+	}{
+		{"entry", false, entry.Link},
+		{"page", true, page.url},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := m
+			if tt.shown {
+				m, _ = update(m, page)
+			}
+			e, ok := m.shownEntry()
+			if !ok || e.Link != tt.want {
+				t.Fatalf("shown %q, want %q", e.Link, tt.want)
+			}
+			if md, _ := entryMarkdown(e); tt.shown && md != page.markdown {
+				t.Errorf("Markdown %q, want the page's %q", md, page.markdown)
+			}
+
+			f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			prevFile, prevClipboard := output.File, writeClipboard
+			t.Cleanup(func() { output.File, writeClipboard = prevFile, prevClipboard })
+			output.File = f
+			var copied string
+			writeClipboard = func(text string) error {
+				copied = text
+				return errors.New("no clipboard")
+			}
+			_, cmd := m.yank("link")
+			cmd()
+			if copied != tt.want {
+				t.Errorf("copied %q, want %q", copied, tt.want)
+			}
+
+			_, cmd = m.pipe("url cat")
+			msg := cmd().(pipeMsg)
+			if msg.err != nil || strings.TrimSpace(msg.output) != tt.want {
+				t.Errorf("piped %q (%v), want %q", msg.output, msg.err, tt.want)
+			}
+		})
+	}
+}
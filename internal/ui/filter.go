@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// entriesLabel renders the sort and filters of the listed entries, shown
+// next to the entries pane title
+func (m Model) entriesLabel() string {
+	if m.currentFeed.ID == 0 {
+		return ""
+	}
+	if m.currentFeed.ID == recentFeedID {
+		return HelpStyle.Render("by time read")
+	}
+	p := m.feedPrefs
+	parts := []string{"by " + string(p.EntrySort)}
+	if p.UnreadOnly {
+		parts = append(parts, "unread")
+	}
+	switch p.DateRange {
+	case db.DateRangeToday:
+		parts = append(parts, "today")
+	case db.DateRangeWeek:
+		parts = append(parts, "this week")
+	case db.DateRangeCustom:
+		switch {
+		case p.DateTo == "":
+			parts = append(parts, "since "+p.DateFrom)
+		case p.DateFrom == "":
+			parts = append(parts, "until "+p.DateTo)
+		default:
+			parts = append(parts, p.DateFrom+" to "+p.DateTo)
+		}
+	}
+	return HelpStyle.Render(strings.Join(parts, " · "))
+}
+
+// updateFeedPrefs changes the saved preferences of the current feed and
+// lists its entries again, keeping the cursor on the same entry.
+func (m Model) updateFeedPrefs(change func(*db.FeedPrefs)) tea.Cmd {
+	feed := m.currentFeed
+	var selectedID int64
+	if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
+		selectedID = i.entry.ID
+	}
+	load := selectEntry(m.loadEntries(feed), selectedID)
+	return func() tea.Msg {
+		prefs, err := db.GetFeedPrefs(feed.ID)
+		if err != nil {
+			return errMsg(err)
+		}
+		change(&prefs)
+		if err := db.SetFeedPrefs(feed.ID, prefs); err != nil {
+			return errMsg(err)
+		}
+		return load()
+	}
+}
+
+func (m Model) toggleUnreadOnly() (tea.Model, tea.Cmd) {
+	if m.currentFeed.ID == 0 {
+		return m, nil
+	}
+	if m.currentFeed.ID == riverFeedID {
+		m.statusMsg = "All unread only lists unread articles"
+		return m, nil
+	}
+	if m.currentFeed.ID == recentFeedID {
+		m.statusMsg = recentOrderNote
+		return m, nil
+	}
+	unreadOnly := !m.feedPrefs.UnreadOnly
+	m.feedPrefs.UnreadOnly = unreadOnly
+	if unreadOnly {
+		m.statusMsg = "Showing unread articles"
+	} else {
+		m.statusMsg = "Showing all articles"
+	}
+	return m, m.updateFeedPrefs(func(p *db.FeedPrefs) { p.UnreadOnly = unreadOnly })
+}
+
+// cycleDateRange steps through no range, today and this week
+func (m Model) cycleDateRange() (tea.Model, tea.Cmd) {
+	switch m.feedPrefs.DateRange {
+	case db.DateRangeAll:
+		return m.setDateRange([]string{"today"})
+	case db.DateRangeToday:
+		return m.setDateRange([]string{"week"})
+	}
+	return m.setDateRange([]string{"all"})
+}
+
+// setDateRange applies the range given to the date_range command: all,
+// today, week, or a custom range of one or two dates where "-" leaves a
+// side open.
+func (m Model) setDateRange(args []string) (tea.Model, tea.Cmd) {
+	if m.currentFeed.ID == 0 {
+		return m, nil
+	}
+	if m.currentFeed.ID == recentFeedID {
+		m.statusMsg = recentOrderNote
+		return m, nil
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return m.commandError("date_range: expected all, today, week or <from> [<to>]")
+	}
+	r := db.DateRange(args[0])
+	from, to := "", ""
+	switch args[0] {
+	case "all":
+		r = db.DateRangeAll
+	case "today", "week":
+	default:
+		r = db.DateRangeCustom
+		from = args[0]
+		if len(args) == 2 {
+			to = args[1]
+		}
+		for _, d := range []*string{&from, &to} {
+			if *d == "-" {
+				*d = ""
+			}
+			if _, err := time.Parse(db.DateLayout, *d); *d != "" && err != nil {
+				return m.commandError("date_range: %q is not a YYYY-MM-DD date", *d)
+			}
+		}
+		if from == "" && to == "" {
+			r = db.DateRangeAll
+		}
+	}
+	if r != db.DateRangeCustom && len(args) > 1 {
+		return m.commandError("date_range: %s takes no dates", args[0])
+	}
+
+	m.feedPrefs.DateRange, m.feedPrefs.DateFrom, m.feedPrefs.DateTo = r, from, to
+	m.statusMsg = ""
+	return m, m.updateFeedPrefs(func(p *db.FeedPrefs) {
+		p.DateRange, p.DateFrom, p.DateTo = r, from, to
+	})
+}
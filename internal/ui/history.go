@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// recentFeedID identifies the virtual "Recently read" feed, which lists the
+// last entries opened in any feed
+const recentFeedID int64 = -2
+
+const (
+	recentSize = 200
+	// maxHistory is how many articles back can go
+	maxHistory = 100
+)
+
+// recentOrderNote explains why the recently read list can't be sorted or
+// filtered
+const recentOrderNote = "Recently read lists the last articles opened, the latest first"
+
+func recentFeed() db.Feed {
+	return db.Feed{ID: recentFeedID, Title: "Recently read"}
+}
+
+func (m Model) loadRecent() tea.Msg {
+	entries, err := db.GetRecentlyRead(recentSize)
+	if err != nil {
+		return errMsg(err)
+	}
+	return entriesMsg{feedID: recentFeedID, entries: entries}
+}
+
+// location is an article visited, for back and forward
+type location struct {
+	feed    db.Feed // listing the entry, can be virtual
+	entryID int64
+	feedID  int64   // of the entry itself
+	scroll  float64 // see saveReadingPosition
+}
+
+// visit records that the article shown is left for another one. Going
+// somewhere new forgets the way forward.
+func (m *Model) visit() {
+	if m.shownEntryID == 0 {
+		return
+	}
+	if n := len(m.back); n > 0 && m.back[n-1].entryID == m.shownEntryID {
+		m.back = m.back[:n-1]
+	}
+	m.back = append(m.back, m.here())
+	if len(m.back) > maxHistory {
+		m.back = m.back[len(m.back)-maxHistory:]
+	}
+	m.forward = nil
+}
+
+// here is the location of the article shown
+func (m Model) here() location {
+	return location{feed: m.shownFeed, entryID: m.shownEntryID, feedID: m.shownFeedID, scroll: m.viewport.ScrollPercent()}
+}
+
+// goBack returns to the article shown before, or with forward set to the
+// one back was used to leave
+func (m Model) goBack(forward bool) (tea.Model, tea.Cmd) {
+	from, to := &m.back, &m.forward
+	if forward {
+		from, to = to, from
+	}
+	if len(*from) == 0 {
+		if forward {
+			m.statusMsg = "No next article in the history"
+		} else {
+			m.statusMsg = "No previous article in the history"
+		}
+		return m, nil
+	}
+	loc := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	if m.shownEntryID != 0 {
+		*to = append(*to, m.here())
+	}
+	return m.goTo(loc)
+}
+
+// goTo shows the article at loc, in its feed, where it was scrolled to
+func (m Model) goTo(loc location) (tea.Model, tea.Cmd) {
+	m.jump = &loc
+	if loc.feed.ID == m.currentFeed.ID {
+		for idx, it := range m.entriesList.VisibleItems() {
+			if e, ok := it.(entryItem); ok && e.entry.ID == loc.entryID {
+				m.entriesList.Select(idx)
+				return m, m.viewSelected()
+			}
+		}
+	}
+	if loc.feed.ID == riverFeedID {
+		// Read since, it is no longer in the river
+		loc.feed = db.Feed{ID: loc.feedID}
+	}
+	if m.feedsList.FilterState() != list.Unfiltered {
+		m.feedsList.ResetFilter()
+	}
+	for idx, it := range m.feedsList.Items() {
+		if fi, ok := it.(feedItem); ok && fi.feed.ID == loc.feed.ID {
+			m.feedsList.Select(idx)
+			loc.feed = fi.feed
+			break
+		}
+	}
+	m.currentFeed = loc.feed
+	return m, selectEntry(m.loadEntries(loc.feed), loc.entryID)
+}
@@ -0,0 +1,377 @@
+package ui
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/draw"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/rss"
+	"github.com/jeremiev/lazyrss/internal/xdg"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/ansi/iterm2"
+	"github.com/charmbracelet/x/ansi/kitty"
+	"github.com/charmbracelet/x/ansi/sixel"
+)
+
+const (
+	// maxImageWidth is the width images are downscaled to before being
+	// cached, in pixels
+	maxImageWidth = 1600
+	// maxImageRows is the most lines an image takes in the article
+	maxImageRows = 200
+	// imageLoaders is how many images are downloaded at the same time
+	imageLoaders = 4
+	// maxImageCache is the size the disk cache of images is kept under, in
+	// bytes
+	maxImageCache = 100 << 20
+)
+
+// imagesMsg tells that the images of the article have been loaded
+type imagesMsg struct{}
+
+// imageKey identifies an image drawn at a given width
+type imageKey struct {
+	url  string
+	cols int
+}
+
+// imageStore keeps the images of articles and how they are drawn in the
+// terminal. It is shared by the copies of the Model and used by background
+// commands.
+type imageStore struct {
+	mu      sync.Mutex
+	images  map[string]image.Image
+	pending map[string]bool
+	failed  map[string]bool // shown as links
+	lines   map[imageKey][]string
+}
+
+func newImageStore() *imageStore {
+	return &imageStore{
+		images:  make(map[string]image.Image),
+		pending: make(map[string]bool),
+		failed:  make(map[string]bool),
+		lines:   make(map[imageKey][]string),
+	}
+}
+
+// clearLines drops the drawn images, after a change of protocol
+func (s *imageStore) clearLines() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.lines)
+}
+
+// imageProtocol resolves the images setting of the config to the graphics
+// protocol of the terminal, empty when images can't be shown
+func imageProtocol(setting string) string {
+	switch setting {
+	case config.ImagesOff:
+		return ""
+	case config.ImagesAuto:
+	default:
+		return setting
+	}
+	// Multiplexers need every sequence wrapped to pass it through
+	if os.Getenv("TMUX") != "" || os.Getenv("STY") != "" {
+		return ""
+	}
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case term == "xterm-kitty" || os.Getenv("KITTY_WINDOW_ID") != "" || program == "ghostty":
+		return config.ImagesKitty
+	case program == "iTerm.app" || program == "WezTerm" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return config.ImagesITerm2
+	case strings.HasPrefix(term, "foot") || strings.HasPrefix(term, "mlterm") || strings.Contains(term, "sixel"):
+		return config.ImagesSixel
+	}
+	return ""
+}
+
+// imagesShown reports whether images are drawn in the article rather than
+// shown as links
+func (m Model) imagesShown() bool {
+	return m.showImages && m.imageProtocol != ""
+}
+
+func (m Model) toggleImages() (tea.Model, tea.Cmd) {
+	if m.imageProtocol == "" {
+		m.statusMsg = "This terminal can't show images, see article.images in the config"
+		return m, nil
+	}
+	m.showImages = !m.showImages
+	m.setContent()
+	if !m.showImages {
+		m.statusMsg = "Images shown as links"
+		return m, nil
+	}
+	m.statusMsg = "Images shown"
+	return m, m.loadImages()
+}
+
+// loadImages downloads the images of the article that aren't loaded yet,
+// or reads them from the disk cache
+func (m Model) loadImages() tea.Cmd {
+	if !m.imagesShown() {
+		return nil
+	}
+	store := m.images
+	var urls []string
+	store.mu.Lock()
+	for _, l := range m.links {
+		if !l.image || store.images[l.url] != nil || store.pending[l.url] || store.failed[l.url] {
+			continue
+		}
+		if !strings.HasPrefix(l.url, "http://") && !strings.HasPrefix(l.url, "https://") {
+			store.failed[l.url] = true
+			continue
+		}
+		store.pending[l.url] = true
+		urls = append(urls, l.url)
+	}
+	store.mu.Unlock()
+	if len(urls) == 0 {
+		return nil
+	}
+
+	return func() tea.Msg {
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, imageLoaders)
+		for _, url := range urls {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				img, err := loadImage(url)
+				store.mu.Lock()
+				defer store.mu.Unlock()
+				delete(store.pending, url)
+				if err != nil {
+					store.failed[url] = true
+					return
+				}
+				store.images[url] = img
+			}()
+		}
+		wg.Wait()
+		return imagesMsg{}
+	}
+}
+
+// loadImage reads an image from the disk cache, or downloads it and keeps
+// a downscaled copy there. The cache is best effort, errors writing it are
+// ignored.
+func loadImage(url string) (image.Image, error) {
+	path := imageCachePath(url)
+	if path != "" {
+		if f, err := os.Open(path); err == nil {
+			img, err := png.Decode(f)
+			f.Close()
+			if err == nil {
+				// The cache drops the images used the longest ago first
+				now := time.Now()
+				os.Chtimes(path, now, now)
+				return img, nil
+			}
+		}
+	}
+
+	img, err := rss.FetchImage(url)
+	if err != nil {
+		return nil, err
+	}
+	if b := img.Bounds(); b.Dx() > maxImageWidth {
+		img = scaleImage(img, maxImageWidth, max(b.Dy()*maxImageWidth/b.Dx(), 1))
+	}
+	if path != "" && os.MkdirAll(filepath.Dir(path), 0755) == nil {
+		if f, err := os.Create(path); err == nil {
+			png.Encode(f, img)
+			f.Close()
+			pruneImageCache(filepath.Dir(path), maxImageCache)
+		}
+	}
+	return img, nil
+}
+
+// pruneImageCache removes the images used the longest ago from the cache
+// in dir until it is under limit bytes
+func pruneImageCache(dir string, limit int64) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	var files []fs.FileInfo
+	var total int64
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && info.Mode().IsRegular() {
+			files = append(files, info)
+			total += info.Size()
+		}
+	}
+	slices.SortFunc(files, func(a, b fs.FileInfo) int {
+		return a.ModTime().Compare(b.ModTime())
+	})
+	for _, f := range files {
+		if total <= limit {
+			break
+		}
+		if os.Remove(filepath.Join(dir, f.Name())) == nil {
+			total -= f.Size()
+		}
+	}
+}
+
+// imageCachePath is where the image at url is cached, empty when there is
+// no cache directory
+func imageCachePath(url string) string {
+	dir, err := xdg.CacheDir()
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(dir, "images", hex.EncodeToString(sum[:16])+".png")
+}
+
+// imageLines draws a loaded image at most cols wide, one line per terminal
+// row. It returns nil when the image isn't loaded.
+func (m Model) imageLines(url string, cols int) []string {
+	s := m.images
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	img := s.images[url]
+	if img == nil || cols <= 0 {
+		return nil
+	}
+	key := imageKey{url: url, cols: cols}
+	if lines, ok := s.lines[key]; ok {
+		return lines
+	}
+
+	cw, ch := cellSize()
+	if cw <= 0 || ch <= 0 {
+		cw, ch = 10, 20
+	}
+	b := img.Bounds()
+	// Small images aren't blown up
+	cols = min(cols, (b.Dx()+cw-1)/cw)
+	rows := min(max((b.Dy()*cols*cw/b.Dx()+ch-1)/ch, 1), maxImageRows)
+	scaled := scaleImage(img, cols*cw, rows*ch)
+
+	lines := make([]string, rows)
+	for row := range rows {
+		// Each row is a separate image, so that it scrolls like text and
+		// partly visible images are drawn right
+		strip := image.NewNRGBA(image.Rect(0, 0, cols*cw, ch))
+		draw.Draw(strip, strip.Bounds(), scaled, image.Pt(0, row*ch), draw.Src)
+		lines[row] = drawImageRow(m.imageProtocol, strip, imageID(url, row), cols)
+	}
+	s.lines[key] = lines
+	return lines
+}
+
+// imageID numbers the rows of the images for kitty, which keeps them by ID
+func imageID(url string, row int) int {
+	h := fnv.New32a()
+	h.Write([]byte(url))
+	return int(h.Sum32()&0xffff|1)<<8 | row&0xff
+}
+
+// drawImageRow returns the line showing one row of an image, cols wide
+func drawImageRow(protocol string, strip image.Image, id, cols int) string {
+	var buf bytes.Buffer
+	switch protocol {
+	case config.ImagesKitty:
+		// The row is sent along with placeholder characters that kitty
+		// draws it in, which are text as far as the layout is concerned.
+		// Their color tells which image they show.
+		kitty.EncodeGraphics(&buf, strip, &kitty.Options{
+			Action:           kitty.TransmitAndPut,
+			Transmission:     kitty.Direct,
+			Quite:            2,
+			ID:               id,
+			Format:           kitty.PNG,
+			Columns:          cols,
+			Rows:             1,
+			VirtualPlacement: true,
+			Chunk:            true,
+		})
+		fmt.Fprintf(&buf, "\x1b[38;2;%d;%d;%dm", id>>16&0xff, id>>8&0xff, id&0xff)
+		buf.WriteString(strings.Repeat(string(kitty.Placeholder), cols))
+		buf.WriteString(ansi.ResetStyle)
+		return buf.String()
+	case config.ImagesITerm2:
+		png.Encode(&buf, strip)
+		seq := ansi.ITerm2(iterm2.File{
+			Inline:            true,
+			Width:             iterm2.Cells(cols),
+			Height:            iterm2.Cells(1),
+			IgnoreAspectRatio: true,
+			Size:              int64(buf.Len()),
+			Content:           []byte(base64.StdEncoding.EncodeToString(buf.Bytes())),
+		})
+		return overlay(seq, cols)
+	case config.ImagesSixel:
+		var enc sixel.Encoder
+		enc.Encode(&buf, strip)
+		// Pixels left unset stay transparent
+		return overlay(ansi.SixelGraphics(0, 1, 0, buf.Bytes()), cols)
+	}
+	return ""
+}
+
+// overlay draws an image sequence over cols blank cells. The blanks keep
+// the layout right, the image is drawn on top of them and the cursor put
+// back wherever the protocol leaves it.
+func overlay(seq string, cols int) string {
+	return strings.Repeat(" ", cols) + ansi.CursorBackward(cols) +
+		ansi.SaveCursor + seq + ansi.RestoreCursor + ansi.CursorForward(cols)
+}
+
+// scaleImage resizes img to w×h pixels, averaging the pixels each one
+// covers when downscaling. The source is converted a band of rows at a
+// time, the rows under one row of the result, rather than all at once.
+func scaleImage(img image.Image, w, h int) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	sw, sh := b.Dx(), b.Dy()
+	band := image.NewNRGBA(image.Rect(0, 0, sw, (sh+h-1)/h))
+	for y := range h {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		draw.Draw(band, image.Rect(0, 0, sw, y1-y0), img, image.Pt(b.Min.X, b.Min.Y+y0), draw.Src)
+		for x := range w {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var sum [4]int
+			for sy := range y1 - y0 {
+				row := band.Pix[sy*band.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					for c := range sum {
+						sum[c] += int(row[sx*4+c])
+					}
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			o := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[o+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestScaleImage(t *testing.T) {
+	// Black and white columns, at an offset like a sub-image
+	stripes := image.NewGray(image.Rect(10, 10, 14, 12))
+	for x := 10; x < 14; x++ {
+		for y := 10; y < 12; y++ {
+			if x%2 == 0 {
+				stripes.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	tests := []struct {
+		name string
+		img  image.Image
+		w, h int
+		want []uint8 // gray level of each pixel, row by row
+	}{
+		{"averaged", stripes, 1, 1, []uint8{127}},
+		{"columns kept", stripes, 4, 1, []uint8{255, 0, 255, 0}},
+		{"pairs of columns", stripes, 2, 2, []uint8{127, 127, 127, 127}},
+		{"upscaled", stripes, 8, 4, slices.Repeat([]uint8{255, 255, 0, 0, 255, 255, 0, 0}, 4)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scaleImage(tt.img, tt.w, tt.h)
+			if b := got.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+				t.Fatalf("scaled to %v, want %dx%d", b, tt.w, tt.h)
+			}
+			var gray []uint8
+			for y := range tt.h {
+				for x := range tt.w {
+					c := got.NRGBAAt(x, y)
+					if c.R != c.G || c.G != c.B || c.A != 255 {
+						t.Fatalf("pixel %d,%d is %v, want an opaque gray", x, y, c)
+					}
+					gray = append(gray, c.R)
+				}
+			}
+			if !slices.Equal(gray, tt.want) {
+				t.Errorf("got %v, want %v", gray, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneImageCache(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	// Used from the longest ago to the latest
+	names := []string{"a.png", "b.png", "c.png", "d.png"}
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, make([]byte, 100), 0644); err != nil {
+			t.Fatal(err)
+		}
+		used := now.Add(time.Duration(i-len(names)) * time.Hour)
+		if err := os.Chtimes(path, used, used); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		limit int64
+		want  []string
+	}{
+		{400, []string{"a.png", "b.png", "c.png", "d.png"}},
+		{250, []string{"c.png", "d.png"}},
+		{100, []string{"d.png"}},
+	}
+	for _, tt := range tests {
+		pruneImageCache(dir, tt.limit)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var left []string
+		for _, e := range entries {
+			left = append(left, e.Name())
+		}
+		if !slices.Equal(left, tt.want) {
+			t.Errorf("limit %d left %v, want %v", tt.limit, left, tt.want)
+		}
+	}
+}
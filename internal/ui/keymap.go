@@ -0,0 +1,290 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyScope says where a binding is active. Bindings of the active pane take
+// precedence over global ones, so the same key can mean different things in
+// different panes.
+type keyScope int
+
+const (
+	scopeGlobal keyScope = iota
+	scopeNavigation
+	scopeFeeds
+	scopeEntries
+	scopeContent
+)
+
+var scopeNames = map[keyScope]string{
+	scopeGlobal:     "General",
+	scopeNavigation: "Navigation",
+	scopeFeeds:      "Feeds Pane",
+	scopeEntries:    "Articles Pane",
+	scopeContent:    "Article View",
+}
+
+// keyBinding ties an action name to its keys. The action name is what the
+// config file uses to rebind it. Keys made of several space-separated
+// keystrokes ("g g") are sequences, which is why the space bar is called
+// "space" here.
+type keyBinding struct {
+	action string
+	scope  keyScope
+	key.Binding
+}
+
+type keyMap struct {
+	bindings []keyBinding
+}
+
+func bind(action string, scope keyScope, help string, keys ...string) keyBinding {
+	return keyBinding{
+		action:  action,
+		scope:   scope,
+		Binding: key.NewBinding(key.WithKeys(keys...), key.WithHelp(keysHelp(keys), help)),
+	}
+}
+
+func defaultKeyMap() keyMap {
+	return keyMap{bindings: []keyBinding{
+		bind("help", scopeGlobal, "Show/Hide Help", "?"),
+		bind("quit", scopeGlobal, "Quit", "q"),
+		bind("command_line", scopeGlobal, "Command Line", ":"),
+		bind("next_pane", scopeGlobal, "Next Pane", "tab", "right"),
+		bind("prev_pane", scopeGlobal, "Previous Pane", "shift+tab", "left"),
+		bind("open", scopeGlobal, "Open Article in Browser", "enter"),
+		bind("link_hints", scopeGlobal, "Open / Copy Link by Number", "o"),
+		bind("yank_link", scopeGlobal, "Copy Article Link", "y y"),
+		bind("yank_title", scopeGlobal, "Copy Article Title", "y t"),
+		bind("yank_feed_url", scopeGlobal, "Copy Feed URL", "y f"),
+		bind("yank_markdown", scopeGlobal, "Copy Article as Markdown", "y m"),
+		bind("next_unread", scopeGlobal, "Next Unread Entry", "n"),
+		bind("prev_unread", scopeGlobal, "Previous Unread Entry", "N"),
+		bind("mark_read_next", scopeGlobal, "Mark Read, Next Unread", "m"),
+		bind("toggle_article_view", scopeGlobal, "Toggle Article View", "t"),
+		bind("toggle_dates", scopeGlobal, "Toggle Entry Dates", "d"),
+		bind("add_feed", scopeGlobal, "Add New Feed", "a"),
+		bind("import_opml", scopeGlobal, "Import OPML", "i"),
+		bind("export_opml", scopeGlobal, "Export OPML", "e"),
+		bind("toggle_feed_info", scopeGlobal, "Toggle Feed Info", "v"),
+		bind("refresh_all", scopeGlobal, "Refresh All Feeds", "r"),
+		bind("cycle_theme", scopeGlobal, "Switch Theme", "T"),
+		bind("cycle_sort", scopeGlobal, "Change Sort of Pane", "s"),
+		bind("toggle_unread_only", scopeGlobal, "Toggle Unread Only", "u"),
+		bind("cycle_date_range", scopeGlobal, "Today / This Week / All", "f"),
+		bind("set_date_range", scopeGlobal, "Custom Date Range", "F"),
+		bind("toggle_select", scopeGlobal, "Select Item", "space"),
+		bind("select_range", scopeGlobal, "Select Range", "V"),
+		bind("select_all", scopeGlobal, "Select All Listed", "ctrl+a"),
+		bind("toggle_read", scopeGlobal, "Mark Read / Unread", "x"),
+		bind("toggle_star", scopeGlobal, "Star / Unstar", "*"),
+		bind("export_articles", scopeGlobal, "Export Articles", "E"),
+		bind("undo", scopeGlobal, "Undo", "U"),
+		bind("grow_pane", scopeGlobal, "Widen Pane", ">"),
+		bind("shrink_pane", scopeGlobal, "Narrow Pane", "<"),
+		bind("toggle_maximize", scopeGlobal, "Maximize / Restore Pane", "z"),
+		bind("cycle_layout", scopeGlobal, "Columns / Stacked / Single Pane", "L"),
+		bind("toggle_reader", scopeGlobal, "Fullscreen Reader", "R"),
+		bind("toggle_images", scopeGlobal, "Show Images / Links", "I"),
+		bind("next_article", scopeGlobal, "Next Article", "J"),
+		bind("prev_article", scopeGlobal, "Previous Article", "K"),
+		bind("open_pager", scopeGlobal, "Open Article in $PAGER", "P"),
+		bind("open_editor", scopeGlobal, "Open Article in $EDITOR", "ctrl+e"),
+		bind("pipe", scopeGlobal, "Pipe Article to Command", "|"),
+		bind("back", scopeGlobal, "Back to Previous Article", "[", "alt+left"),
+		bind("forward", scopeGlobal, "Forward to Next Article", "]", "alt+right"),
+
+		bind("up", scopeNavigation, "Move Up", "up", "k"),
+		bind("down", scopeNavigation, "Move Down", "down", "j"),
+		bind("page_up", scopeNavigation, "Page Up", "pgup"),
+		bind("page_down", scopeNavigation, "Page Down", "pgdown"),
+		bind("half_page_up", scopeNavigation, "Half Page Up", "ctrl+u"),
+		bind("half_page_down", scopeNavigation, "Half Page Down", "ctrl+d"),
+		bind("top", scopeNavigation, "Go to Top", "g g", "home"),
+		bind("bottom", scopeNavigation, "Go to Bottom / Line N", "G", "end"),
+		bind("filter", scopeNavigation, "Filter List", "/"),
+
+		bind("move_feed_up", scopeFeeds, "Move Feed Up", "alt+up", "alt+k"),
+		bind("move_feed_down", scopeFeeds, "Move Feed Down", "alt+down", "alt+j"),
+		bind("delete_feed", scopeFeeds, "Delete Feed", "D"),
+		bind("toggle_pause", scopeFeeds, "Pause / Resume Feed", "p"),
+		bind("move_to_folder", scopeFeeds, "Move to Folder", "M"),
+
+		bind("refresh_feed", scopeEntries, "Refresh Current Feed", "r"),
+
+		bind("search_article", scopeContent, "Search Article", "/"),
+		bind("search_next", scopeContent, "Next Match (or Unread)", "n"),
+		bind("search_prev", scopeContent, "Previous Match (or Unread)", "N"),
+	}}
+}
+
+// newKeyMap applies the [keys] overrides from the config file on top of the
+// defaults. Overriding an action replaces all of its default keys.
+func newKeyMap(overrides map[string][]string) (keyMap, error) {
+	km := defaultKeyMap()
+	var problems []string
+
+	actions := make([]string, 0, len(overrides))
+	for action := range overrides {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		b := km.binding(action)
+		if b == nil {
+			problems = append(problems, fmt.Sprintf("keys.%s: unknown action", action))
+			continue
+		}
+		keys := make([]string, len(overrides[action]))
+		for i, k := range overrides[action] {
+			keys[i] = strings.Join(strings.Fields(k), " ")
+			if keys[i] == "" {
+				problems = append(problems, fmt.Sprintf("keys.%s: empty key", action))
+			}
+		}
+		b.SetKeys(keys...)
+		b.SetHelp(keysHelp(keys), b.Help().Desc)
+	}
+
+	problems = append(problems, km.conflicts()...)
+	if len(problems) > 0 {
+		return km, fmt.Errorf("%s", strings.Join(problems, "\n  "))
+	}
+	return km, nil
+}
+
+func (k *keyMap) binding(action string) *keyBinding {
+	for i := range k.bindings {
+		if k.bindings[i].action == action {
+			return &k.bindings[i]
+		}
+	}
+	return nil
+}
+
+// conflicts reports keys that are bound twice where both bindings can be
+// active at the same time, or where one sequence shadows a longer one.
+func (k keyMap) conflicts() []string {
+	var problems []string
+	for i, a := range k.bindings {
+		for _, b := range k.bindings[i+1:] {
+			if !scopesOverlap(a.scope, b.scope) {
+				continue
+			}
+			for _, ka := range a.Keys() {
+				for _, kb := range b.Keys() {
+					if ka == kb || strings.HasPrefix(ka, kb+" ") || strings.HasPrefix(kb, ka+" ") {
+						problems = append(problems, fmt.Sprintf("keys: %q (%s) conflicts with %q (%s)", ka, a.action, kb, b.action))
+					}
+				}
+			}
+		}
+	}
+	return problems
+}
+
+// scopesOverlap reports whether bindings of the two scopes can fire in the
+// same pane without one of them taking precedence.
+func scopesOverlap(a, b keyScope) bool {
+	isShared := func(s keyScope) bool { return s == scopeGlobal || s == scopeNavigation }
+	if isShared(a) && isShared(b) {
+		return true
+	}
+	return a == b
+}
+
+// match looks up the action bound to the keystrokes typed so far in the
+// given pane scope. prefix reports that seq starts a longer sequence and more
+// keystrokes should be awaited.
+func (k keyMap) match(seq string, pane keyScope) (action string, prefix bool) {
+	for _, scopes := range [][]keyScope{{pane}, {scopeGlobal, scopeNavigation}} {
+		for _, b := range k.bindings {
+			if !b.Enabled() || !containsScope(scopes, b.scope) {
+				continue
+			}
+			for _, ks := range b.Keys() {
+				if ks == seq {
+					return b.action, false
+				}
+				if strings.HasPrefix(ks, seq+" ") {
+					prefix = true
+				}
+			}
+		}
+		if prefix {
+			return "", true
+		}
+	}
+	return "", false
+}
+
+func containsScope(scopes []keyScope, s keyScope) bool {
+	for _, x := range scopes {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// groups returns the bindings of each scope in display order, for the help
+// screen.
+func (k keyMap) groups() ([]string, [][]key.Binding) {
+	var names []string
+	var groups [][]key.Binding
+	for _, s := range []keyScope{scopeGlobal, scopeNavigation, scopeFeeds, scopeEntries, scopeContent} {
+		var group []key.Binding
+		for _, b := range k.bindings {
+			if b.scope == s && b.Enabled() && len(b.Keys()) > 0 {
+				group = append(group, b.Binding)
+			}
+		}
+		if len(group) > 0 {
+			names = append(names, scopeNames[s])
+			groups = append(groups, group)
+		}
+	}
+	return names, groups
+}
+
+var keyNames = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"tab":       "Tab",
+	"shift+tab": "S-Tab",
+	"enter":     "Enter",
+	"esc":       "Esc",
+	"pgup":      "PgUp",
+	"pgdown":    "PgDn",
+	"home":      "Home",
+	"end":       "End",
+	"space":     "Space",
+}
+
+// keysHelp formats keys for display, "g g" becomes "gg" and named keys get
+// their symbols.
+func keysHelp(keys []string) string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		parts := strings.Fields(k)
+		for j, p := range parts {
+			if name, ok := keyNames[p]; ok {
+				parts[j] = name
+			} else if strings.HasPrefix(p, "alt+") {
+				if name, ok := keyNames[strings.TrimPrefix(p, "alt+")]; ok {
+					parts[j] = "alt+" + name
+				}
+			}
+		}
+		out[i] = strings.Join(parts, "")
+	}
+	return strings.Join(out, " / ")
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		problem   string // part of the error, empty when valid
+	}{
+		{"defaults", nil, ""},
+		{"rebind", map[string][]string{"quit": {"Q"}, "refresh_feed": {"ctrl+r"}}, ""},
+		{"spaces in sequences", map[string][]string{"top": {"g   g"}}, ""},
+		{"same key in different panes", map[string][]string{"delete_feed": {"r"}}, ""},
+		{"unknown action", map[string][]string{"fly": {"f"}}, "keys.fly: unknown action"},
+		{"empty key", map[string][]string{"quit": {" "}}, "keys.quit: empty key"},
+		{"global conflict", map[string][]string{"quit": {"d"}}, `"d" (quit) conflicts with "d" (toggle_dates)`},
+		{"key shadowing a sequence", map[string][]string{"quit": {"g"}}, `"g" (quit) conflicts with "g g" (top)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newKeyMap(tt.overrides)
+			switch {
+			case tt.problem == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.problem != "" && err == nil:
+				t.Fatalf("no error, want %q", tt.problem)
+			case tt.problem != "" && !strings.Contains(err.Error(), tt.problem):
+				t.Fatalf("error %q doesn't mention %q", err, tt.problem)
+			}
+		})
+	}
+}
+
+func TestNewKeyMapSequences(t *testing.T) {
+	km, err := newKeyMap(map[string][]string{"top": {"g   g", "home"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(km.binding("top").Keys(), ","); got != "g g,home" {
+		t.Errorf("top bound to %q, want \"g g,home\"", got)
+	}
+}
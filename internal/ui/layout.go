@@ -0,0 +1,264 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// resizeStep is how much of the window a resize key moves a border
+	resizeStep = 0.02
+
+	// Narrowest widths of the panes, their content without the borders
+	minFeedsWidth   = 20
+	minEntriesWidth = 25
+	minContentWidth = 30
+
+	// Narrowest window for each layout in auto mode, below them the next
+	// one is used
+	autoColumnsWidth  = 100
+	autoStackedWidth  = 60
+	autoStackedHeight = 20
+)
+
+// layoutMsg carries the pane ratios saved by an earlier resize
+type layoutMsg struct {
+	feeds, entries float64
+}
+
+// pane borders that can be dragged with the mouse
+const (
+	noBorder = iota
+	feedsBorder
+	entriesBorder
+)
+
+func (m Model) loadLayout() tea.Msg {
+	feeds, entries, err := db.GetLayoutRatios()
+	if err != nil {
+		return errMsg(err)
+	}
+	return layoutMsg{feeds: feeds, entries: entries}
+}
+
+func (m Model) saveLayout() tea.Cmd {
+	feeds, entries := m.feedsRatio, m.entriesRatio
+	return func() tea.Msg {
+		if err := db.SetLayoutRatios(feeds, entries); err != nil {
+			return errMsg(err)
+		}
+		return nil
+	}
+}
+
+// ratios returns the share of the window given to the feeds and entries
+// panes: the resized ones, or the config's.
+func (m Model) ratios() (feeds, entries float64) {
+	if m.feedsRatio > 0 && m.entriesRatio > 0 {
+		return m.feedsRatio, m.entriesRatio
+	}
+	return m.cfg.Layout.FeedsRatio, m.cfg.Layout.EntriesRatio
+}
+
+// setRatios resizes the panes, down to their narrowest widths
+func (m *Model) setRatios(feeds, entries float64) {
+	available := float64(m.availableWidth())
+	if available > 0 {
+		feeds = max(feeds, minFeedsWidth/available)
+		entries = max(entries, minEntriesWidth/available)
+		if m.columns() == 3 {
+			// Only the border that moved stops
+			if maxLists := 1 - minContentWidth/available; feeds+entries > maxLists {
+				if f, _ := m.ratios(); f != feeds {
+					feeds = maxLists - entries
+				} else {
+					entries = maxLists - feeds
+				}
+			}
+		} else {
+			feeds = min(feeds, 1-minEntriesWidth/available)
+		}
+	}
+	m.feedsRatio, m.entriesRatio = feeds, entries
+	m.recalcPaneDimensions()
+}
+
+// availableWidth is the width shared by the panes side by side, without
+// their borders
+func (m Model) availableWidth() int {
+	return m.width - 2*m.columns()
+}
+
+// columns returns how many panes are shown side by side
+func (m Model) columns() int {
+	switch {
+	case m.singlePane():
+		return 1
+	case m.showArticleView && m.currentLayout() == config.LayoutColumns:
+		return 3
+	}
+	return 2
+}
+
+// currentLayout resolves the auto layout mode for the window size
+func (m Model) currentLayout() string {
+	if m.layoutMode != config.LayoutAuto {
+		return m.layoutMode
+	}
+	switch {
+	case m.width <= 0 || m.width >= autoColumnsWidth:
+		return config.LayoutColumns
+	case m.width >= autoStackedWidth && m.height >= autoStackedHeight:
+		return config.LayoutStacked
+	}
+	return config.LayoutSingle
+}
+
+// singlePane reports whether only the active pane is shown
+func (m Model) singlePane() bool {
+	return m.maximized || m.reader || m.currentLayout() == config.LayoutSingle
+}
+
+// setLayout switches to a layout mode for this session, the config sets
+// the one to start with
+func (m Model) setLayout(mode string) (tea.Model, tea.Cmd) {
+	if !slices.Contains(config.LayoutModes, mode) {
+		return m.commandError("Unknown layout: %s", mode)
+	}
+	m.layoutMode = mode
+	cmd := m.relayout()
+	m.statusMsg = "Layout: " + mode
+	if mode == config.LayoutAuto {
+		m.statusMsg += " (" + m.currentLayout() + ")"
+	}
+	return m, cmd
+}
+
+// drillDown moves to the pane showing what is selected, for the single
+// pane layout where the others can't be seen. It reports false when there
+// is no such pane.
+func (m *Model) drillDown() bool {
+	switch {
+	case m.activePane == paneFeeds:
+		m.activePane = paneEntries
+	case m.activePane == paneEntries && m.showArticleView:
+		m.activePane = paneContent
+	default:
+		return false
+	}
+	return true
+}
+
+// drillUp goes back to the pane drillDown came from
+func (m *Model) drillUp() {
+	if m.activePane > paneFeeds {
+		m.activePane--
+	}
+}
+
+// resizePane grows the active pane by steps, or shrinks it when steps is
+// negative. The article pane grows at the expense of both lists.
+func (m Model) resizePane(steps int) (tea.Model, tea.Cmd) {
+	if m.singlePane() {
+		return m, nil
+	}
+	feeds, entries := m.ratios()
+	delta := float64(steps) * resizeStep
+	switch {
+	case m.activePane == paneFeeds:
+		m.setRatios(feeds+delta, entries)
+	case m.columns() == 2:
+		// The panes right of the feeds take what the feeds pane leaves
+		m.setRatios(feeds-delta, entries)
+	case m.activePane == paneEntries:
+		m.setRatios(feeds, entries+delta)
+	default:
+		m.setRatios(feeds-delta/2, entries-delta/2)
+	}
+	cmd := m.relayout()
+	return m, tea.Batch(cmd, m.saveLayout())
+}
+
+// resetLayout goes back to the pane widths of the config
+func (m Model) resetLayout() (tea.Model, tea.Cmd) {
+	m.feedsRatio, m.entriesRatio = 0, 0
+	cmd := m.relayout()
+	m.statusMsg = "Pane widths reset"
+	return m, tea.Batch(cmd, m.saveLayout())
+}
+
+// toggleMaximize gives the whole window to the active pane until it is
+// toggled again. It isn't saved.
+func (m Model) toggleMaximize() (tea.Model, tea.Cmd) {
+	m.maximized = !m.maximized
+	cmd := m.relayout()
+	return m, cmd
+}
+
+// borderAt returns the pane border under column x, if any. Each pane is
+// drawn 2 columns wider than its content for its own borders.
+func (m Model) borderAt(x int) int {
+	if m.singlePane() {
+		return noBorder
+	}
+	feedsEdge := m.feedsWidth + 1
+	entriesEdge := feedsEdge + m.entriesWidth + 2
+	switch {
+	case x == feedsEdge || x == feedsEdge+1:
+		return feedsBorder
+	case m.columns() == 3 && (x == entriesEdge || x == entriesEdge+1):
+		return entriesBorder
+	}
+	return noBorder
+}
+
+// updateDrag resizes the panes while a border is dragged, it reports
+// whether the mouse event was used.
+func (m *Model) updateDrag(msg tea.MouseMsg) (bool, tea.Cmd) {
+	switch {
+	case msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft:
+		m.dragging = m.borderAt(msg.X)
+		return m.dragging != noBorder, nil
+	case m.dragging == noBorder:
+		return false, nil
+	case msg.Action == tea.MouseActionRelease:
+		m.dragging = noBorder
+		// The article is wrapped again once the border is dropped
+		return true, tea.Batch(m.relayout(), m.saveLayout())
+	}
+
+	available := float64(m.availableWidth())
+	if available <= 0 {
+		return true, nil
+	}
+	feeds, entries := m.ratios()
+	if m.dragging == feedsBorder {
+		m.setRatios(float64(msg.X-1)/available, entries)
+	} else {
+		m.setRatios(feeds, float64(msg.X-m.feedsWidth-3)/available)
+	}
+	return true, nil
+}
+
+// paneAt returns the pane under the cell x, y
+func (m Model) paneAt(x, y int) state {
+	switch {
+	case m.singlePane():
+		return m.activePane
+	case x < m.feedsList.Width():
+		return paneFeeds
+	case m.columns() == 2 && m.showArticleView:
+		// Stacked, the article is below the articles list
+		if y < m.entriesHeight+2 {
+			return paneEntries
+		}
+		return paneContent
+	case !m.showArticleView || x < m.feedsList.Width()+m.entriesList.Width()+2:
+		return paneEntries
+	}
+	return paneContent
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLayoutBySize(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		width, height int
+		maximized     bool
+		layout        string
+		columns       int
+		// widths of the panes' content and heights of the articles list
+		// and the article, borders excluded for the widths
+		feeds, entries, content      int
+		entriesHeight, contentHeight int
+	}{
+		{"wide", config.LayoutAuto, 120, 40, false, config.LayoutColumns, 3, 22, 28, 64, 37, 37},
+		{"medium", config.LayoutAuto, 80, 40, false, config.LayoutStacked, 2, 20, 56, 56, 14, 21},
+		{"narrow", config.LayoutAuto, 50, 40, false, config.LayoutSingle, 1, 48, 48, 48, 37, 37},
+		{"short", config.LayoutAuto, 80, 15, false, config.LayoutSingle, 1, 78, 78, 78, 12, 12},
+		{"stacked when wide", config.LayoutStacked, 120, 40, false, config.LayoutStacked, 2, 23, 93, 93, 14, 21},
+		{"maximized", config.LayoutAuto, 120, 40, true, config.LayoutColumns, 1, 118, 118, 118, 37, 37},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+			m.layoutMode, m.maximized, m.showArticleView = tt.mode, tt.maximized, true
+			m, _ = update(m, tea.WindowSizeMsg{Width: tt.width, Height: tt.height})
+			if got := m.currentLayout(); got != tt.layout {
+				t.Errorf("layout %s, want %s", got, tt.layout)
+			}
+			if got := m.columns(); got != tt.columns {
+				t.Errorf("%d columns, want %d", got, tt.columns)
+			}
+			got := [5]int{m.feedsWidth, m.entriesWidth, m.contentWidth, m.entriesHeight, m.contentHeight}
+			want := [5]int{tt.feeds, tt.entries, tt.content, tt.entriesHeight, tt.contentHeight}
+			if got != want {
+				t.Errorf("feeds, entries and content widths, then heights %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestResizePane(t *testing.T) {
+	tests := []struct {
+		name    string
+		pane    state
+		steps   int
+		feeds   int // widths of the panes' content
+		entries int
+	}{
+		{"grow feeds", paneFeeds, 5, 34, 28},
+		{"shrink feeds to their narrowest", paneFeeds, -50, minFeedsWidth, 28},
+		// Widths are rounded down, the article gets the column left
+		{"grow feeds up to the article", paneFeeds, 50, 114 - 28 - minContentWidth - 1, 28},
+		{"grow entries", paneEntries, 5, 22, 39},
+		{"grow the article", paneContent, 10, minFeedsWidth, minEntriesWidth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+			m.showArticleView, m.activePane = true, tt.pane
+			m, _ = update(m, tea.WindowSizeMsg{Width: 120, Height: 40})
+			m = step(m.resizePane(tt.steps))
+			if m.feedsWidth != tt.feeds || m.entriesWidth != tt.entries {
+				t.Errorf("feeds %d and entries %d wide, want %d and %d", m.feedsWidth, m.entriesWidth, tt.feeds, tt.entries)
+			}
+			if total := m.feedsWidth + m.entriesWidth + m.contentWidth + 6; total != 120 {
+				t.Errorf("panes take %d columns, want 120", total)
+			}
+
+			// Resetting goes back to the ratios of the config
+			m = step(m.resetLayout())
+			if m.feedsWidth != 22 || m.entriesWidth != 28 {
+				t.Errorf("feeds %d and entries %d wide after reset, want 22 and 28", m.feedsWidth, m.entriesWidth)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jeremiev/lazyrss/internal/rss"
+
+	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// articleLink is a link or an image of the displayed article. Its hint
+// number is its position in the article, starting at 1.
+type articleLink struct {
+	text  string
+	url   string
+	image bool
+}
+
+// linkAction is what happens to the link picked in hint mode
+type linkAction int
+
+const (
+	linkOpen linkAction = iota
+	linkYank
+	linkRead
+)
+
+var linkActionNames = map[linkAction]string{
+	linkOpen: "open",
+	linkYank: "copy",
+	linkRead: "read here",
+}
+
+// linkHint is the state of the link hint mode, where links are picked by
+// typing their number
+type linkHint struct {
+	input  string
+	action linkAction
+}
+
+// linkToken stands for the i-th link in rendered content until expandLinks
+// replaces it. Glamour leaves it alone, it has no Markdown in it.
+func linkToken(i int) string {
+	return fmt.Sprintf("GLAMOURTOKEN%dURL", i)
+}
+
+// expandLinks turns the link tokens of content into OSC 8 hyperlinks,
+// followed by their hint number when numbered is set. Images that images
+// can draw are shown above their link, it is nil when images are off.
+func expandLinks(content string, links []articleLink, numbered bool, images func(url string) []string) string {
+	for i, l := range links {
+		text := l.text
+		if l.image {
+			text = "[img] " + text
+		}
+		link := fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", l.url, LinkStyle.Render(text))
+		if numbered {
+			link += LinkHintStyle.Render(fmt.Sprintf("[%d]", i+1))
+		}
+		if l.image && images != nil {
+			if lines := images(l.url); lines != nil {
+				link = "\n  " + strings.Join(lines, "\n  ") + "\n  " + link
+			}
+		}
+		content = strings.ReplaceAll(content, linkToken(i), link)
+	}
+	return content
+}
+
+// setContent shows the rendered article in the content pane, with link
+// numbers in hint mode or when footnotes are enabled
+func (m *Model) setContent() {
+	numbered := m.state == stateLinkHint || m.cfg.Article.Footnotes
+	var images func(url string) []string
+	if m.imagesShown() {
+		images = func(url string) []string {
+			// Within the margins of the article
+			return m.imageLines(url, m.wrapWidth()-4)
+		}
+	}
+	m.viewport.SetContent(m.highlightMatches(m.articleText(numbered, m.cfg.Article.Footnotes, images)))
+}
+
+// articleText is the rendered article with its links expanded, and listed
+// at the end when footnotes is set
+func (m Model) articleText(numbered, footnotes bool, images func(url string) []string) string {
+	content := expandLinks(m.content, m.links, numbered, images)
+	if footnotes && len(m.links) > 0 {
+		refs := make([]string, len(m.links))
+		for i, l := range m.links {
+			refs[i] = LinkHintStyle.Render(fmt.Sprintf("[%d]", i+1)) + " " + l.url
+		}
+		content += "\n\n" + MetaStyle.Render(LabelStyle.Render("Links")+"\n"+strings.Join(refs, "\n"))
+	}
+	return content
+}
+
+// startLinkHint numbers the links of the article and waits for one to be
+// picked. With a count the link of that number is opened right away.
+func (m Model) startLinkHint(count int) (tea.Model, tea.Cmd) {
+	if len(m.links) == 0 {
+		m.statusMsg = "No links in this article"
+		return m, nil
+	}
+	if count > 0 {
+		return m.followLink(count, linkOpen)
+	}
+	m.hint = linkHint{}
+	m.state = stateLinkHint
+	m.setContent()
+	return m, nil
+}
+
+func (m Model) updateLinkHint(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := msg.String()
+	action, isAction := map[string]linkAction{"enter": linkOpen, "o": linkOpen, "y": linkYank, "r": linkRead}[k]
+	switch {
+	case k == "esc" || k == "q" || k == "ctrl+c":
+		return m.stopLinkHint(), nil
+	case k == "backspace":
+		if m.hint.input == "" {
+			return m.stopLinkHint(), nil
+		}
+		m.hint.input = m.hint.input[:len(m.hint.input)-1]
+	case isAction && m.hint.input != "":
+		n, _ := strconv.Atoi(m.hint.input)
+		return m.stopLinkHint().followLink(n, action)
+	case isAction && k != "enter":
+		// The action can be picked before the number
+		m.hint.action = action
+	case len(k) == 1 && k[0] >= '0' && k[0] <= '9' && (k != "0" || m.hint.input != ""):
+		n, _ := strconv.Atoi(m.hint.input + k)
+		if n > len(m.links) {
+			return m, nil
+		}
+		m.hint.input += k
+		// Follow the link as soon as no other number starts with this one
+		if n*10 > len(m.links) {
+			return m.stopLinkHint().followLink(n, m.hint.action)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) stopLinkHint() Model {
+	m.state = stateMain
+	m.setContent()
+	return m
+}
+
+// hintPrompt is shown in the status bar in hint mode
+func (m Model) hintPrompt() string {
+	if m.hint.input == "" {
+		return fmt.Sprintf("Link to %s (1-%d): type its number · o open · y copy · r read here · esc cancel",
+			linkActionNames[m.hint.action], len(m.links))
+	}
+	return fmt.Sprintf("Link %s: enter %s · o open · y copy · r read here · esc cancel",
+		m.hint.input, linkActionNames[m.hint.action])
+}
+
+// followLink applies action to the link numbered n
+func (m Model) followLink(n int, action linkAction) (tea.Model, tea.Cmd) {
+	if n < 1 || n > len(m.links) {
+		m.statusMsg = ErrorStyle.Render(fmt.Sprintf("No link %d, this article has %d", n, len(m.links)))
+		return m, nil
+	}
+	l := m.links[n-1]
+	switch action {
+	case linkYank:
+		return m, copyText(l.url, fmt.Sprintf("link %d", n), true)
+	case linkRead:
+		m.loading = true
+		return m, m.readPage(l.url)
+	}
+	m.statusMsg = fmt.Sprintf("Opened link %d", n)
+	return m, m.openURL(l.url)
+}
+
+// readPage fetches a web page and shows its main content in the content
+// pane, the way articles are shown
+func (m Model) readPage(url string) tea.Cmd {
+	return func() tea.Msg {
+		page, err := rss.FetchPage(url)
+		if err != nil {
+			return exportMsg(ErrorStyle.Render("Reader: " + err.Error()))
+		}
+		md, err := htmltomarkdown.ConvertString(page.HTML, converter.WithDomain(url))
+		if err != nil {
+			return exportMsg(ErrorStyle.Render("Reader: " + err.Error()))
+		}
+		title := page.Title
+		if title == "" {
+			title = url
+		}
+		return m.renderPage(title, url, md, page.HTML)
+	}
+}
+
+// renderPage renders the Markdown of a page opened from a link, html being
+// what it was converted from
+func (m Model) renderPage(title, url, md, html string) contentMsg {
+	var links []articleLink
+	out := MetaStyle.Render(fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", url, LinkStyle.Render(url))) + "\n\n" +
+		m.renderMarkdown(md, &links)
+	return contentMsg{text: "\n" + out, links: links, title: title, url: url, markdown: md, html: html}
+}
@@ -1,14 +1,12 @@
 package ui
 
 import (
+	"github.com/jeremiev/lazyrss/internal/config"
 	"github.com/jeremiev/lazyrss/internal/db"
 	"github.com/jeremiev/lazyrss/internal/rss"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
-	"regexp"
-	"runtime"
 	"strings"
 	"time"
 
@@ -22,6 +20,7 @@ import (
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	htmltomarkdown "github.com/JohannesKaufmann/html-to-markdown/v2"
+	"github.com/JohannesKaufmann/html-to-markdown/v2/converter"
 	"github.com/mattn/go-runewidth"
 )
 
@@ -40,6 +39,11 @@ const (
 	stateImportingOPML
 	stateExportingOPML
 	stateHelp
+	stateCommand
+	stateConfirm
+	stateLinkHint
+	stateSearch
+	stateOutput
 )
 
 type errMsg error
@@ -55,49 +59,64 @@ func (d noSpacingDelegate) Spacing() int {
 
 type feedItem struct {
 	feed db.Feed
+	// selection is shared with the model, so marks follow it without
+	// rebuilding the items
+	selection map[int64]bool
 }
 
 func (i feedItem) Title() string {
+	title := i.feed.Title
 	if i.feed.UnreadCount > 0 {
-		return fmt.Sprintf("%s (%d)", i.feed.Title, i.feed.UnreadCount)
+		title = fmt.Sprintf("%s (%d)", i.feed.Title, i.feed.UnreadCount)
 	}
-	return i.feed.Title
+	if i.feed.Paused {
+		title += DateStyle.Render(" (paused)")
+	}
+	if i.feed.Folder != "" {
+		title = DateStyle.Render(i.feed.Folder+"/") + title
+	}
+	if i.selection[i.feed.ID] {
+		title = SelectedMarkStyle.Render("● ") + title
+	}
+	return title
 }
 func (i feedItem) Description() string { return "" }
 func (i feedItem) FilterValue() string { return i.feed.Title }
 
 type entryItem struct {
-	entry          db.Entry
-	feedLastReadAt time.Time
-	showDates      bool
+	entry     db.Entry
+	showDates bool
+	dates     dateFormat
+	selection map[int64]bool
 }
 
 func (i entryItem) Title() string {
 	title := i.entry.Title
-	if i.entry.PublishedAt.After(i.feedLastReadAt) {
+	if i.entry.Read {
+		title = ReadItemStyle.Render(i.entry.Title)
+	} else {
 		title = UnreadItemStyle.Render(i.entry.Title)
 	}
+	if i.entry.Starred {
+		title = SelectedMarkStyle.Render("★ ") + title
+	}
+	// Entries from the river come from several feeds
+	if i.entry.FeedTitle != "" {
+		title = DateStyle.Render(i.entry.FeedTitle+" ·") + " " + title
+	}
 	
 	// Add date prefix if enabled
 	if i.showDates && !i.entry.PublishedAt.IsZero() {
-		var dateStr string
-		now := time.Now()
-		if i.entry.PublishedAt.Year() == now.Year() {
-			// Same year: "19 Feb" or " 9 Feb" (space before day if < 10)
-			// Use " 2" format to pad single-digit days with a space
-			dateStr = i.entry.PublishedAt.Format("2 Jan")
-			if i.entry.PublishedAt.Day() < 10 {
-				dateStr = " " + dateStr
-			}
-		} else {
-			// Different year: "  2025" (extra space before year)
-			dateStr = "  " + i.entry.PublishedAt.Format("2006")
-		}
+		dateStr := i.dates.listDate(i.entry.PublishedAt, i.dates.now())
 		// Style the date with a dimmer color
 		styledDate := DateStyle.Render(dateStr)
 		title = styledDate + " " + title
 	}
 	
+	if i.selection[i.entry.ID] {
+		title = SelectedMarkStyle.Render("● ") + title
+	}
+
 	// Wrap the title text in an OSC 8 hyperlink
 	return "\x1b]8;;" + i.entry.Link + "\x1b\\" + title + "\x1b]8;;\x1b\\"
 }
@@ -119,28 +138,94 @@ type Model struct {
 	height      int
 	currentFeed db.Feed
 	renderer    *glamour.TermRenderer
+	rendererWidth   int          // wrap width the renderer was made for
+	renderCache     *renderCache // shared by the copies of the Model
 	initialLoadDone bool
 	syncPending     int
 	statusMsg       string
 	showFeedInfo    bool
 	showArticleView bool
 	showEntryDates  bool
+	dates           dateFormat
+	profile         string
+	theme           Theme
+	darkBackground  bool
+	keys            keyMap
+	pendingKeys     string // keystrokes of an unfinished sequence
+	count           int    // numeric prefix typed before an action
+	commandInput    textinput.Model
+	commandHistory  []string
+	historyIdx      int
+	completions     []string
+	completionIdx   int
+	completionBase  string // command line text before the completed word
+	cfg             *config.Config
+	configPath      string
+	configModTime   time.Time
+	refreshGen      int
+	riverMore       bool // the river has entries past the loaded pages
+	riverLoading    bool
+	feedSort        db.FeedSort
+	feedPrefs       db.FeedPrefs // sort and filters of the listed entries
+	selectedFeeds    map[int64]bool
+	selectedEntryIDs map[int64]bool
+	rangeAnchor      int // list index where a range selection started, or -1
+	rangePane        state
+	confirm          confirmDialog
+	output           outputPopup // output of a piped command
+	shownEntryID     int64       // entry in the content pane, 0 for pages
+	shownFeedID      int64       // its feed
+	shownFeed        db.Feed     // feed it was opened from, can be virtual
+	shownPosition    float64     // its reading position when it was opened
+	back, forward    []location  // articles visited, the latest last
+	jump             *location   // set while going back or forward
+	undoStack        []undoStep
+	content          string        // rendered article, see contentMsg
+	links            []articleLink // links of the article, by hint number - 1
+	pageTitle        string        // page opened from a link instead of the entry
+	pageURL          string
+	pageMD           string // Markdown of the page, to render it again
+	pageHTML         string // HTML of the page, for the programs it is sent to
+	hint             linkHint
+	feedsRatio       float64 // pane widths set by resizing, 0 for the config's
+	entriesRatio     float64
+	maximized        bool // the active pane takes the whole window
+	dragging         int  // pane border being dragged with the mouse
+	layoutMode       string // a config.LayoutModes, auto picks by window size
+	reader           bool   // fullscreen reader, see toggleReader
+	images           *imageStore // shared by the copies of the Model
+	imageProtocol    string      // graphics protocol of the terminal, empty for none
+	showImages       bool
+	readerPane       state  // pane to go back to when leaving the reader
+	search           articleSearch
+	searchInput      textinput.Model
+	searchFrom       int // scroll position when the search started
 	// Stored pane dimensions for consistent rendering
-	paneHeight   int
-	feedsWidth   int
-	entriesWidth int
-	contentWidth int
+	paneHeight    int
+	entriesHeight int
+	contentHeight int
+	feedsWidth    int
+	entriesWidth  int
+	contentWidth  int
 }
 
-func NewModel() Model {
+func NewModel(cfg *config.Config, configPath, profile string) (Model, error) {
+	keys, err := newKeyMap(cfg.Keys)
+	if err != nil {
+		return Model{}, fmt.Errorf("%s:\n  %w", configPath, err)
+	}
+
+
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
 
 	ti := textinput.New()
 	ti.Placeholder = "RSS Feed URL"
 	ti.Focus()
 
+	ci := textinput.New()
+	ci.Prompt = ":"
+
 	fp := filepicker.New()
 	fp.AllowedTypes = []string{".opml", ".xml"}
 	fp.CurrentDirectory, _ = os.UserHomeDir()
@@ -153,14 +238,29 @@ func NewModel() Model {
 		entriesList:    list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0),
 		viewport:       viewport.New(0, 0),
 		textInput:      ti,
+		commandInput:   ci,
+		searchInput:    newSearchInput(),
 		filePicker:     fp,
 		spinner:        s,
 		loading:        true, // Set to true initially so the user sees the spinner immediately
+		profile:        profile,
+		cfg:            cfg,
+		configPath:     configPath,
+		keys:           keys,
+		layoutMode:     cfg.Layout.Mode,
+		dates:          newDateFormat(cfg.Dates),
+		renderCache:    newRenderCache(),
+		images:         newImageStore(),
+		imageProtocol:  imageProtocol(cfg.Article.Images),
+		showImages:     true,
+		darkBackground: lipgloss.HasDarkBackground(),
+		selectedFeeds:    make(map[int64]bool),
+		selectedEntryIDs: make(map[int64]bool),
+		rangeAnchor:      -1,
+	}
+	if info, err := os.Stat(configPath); err == nil {
+		m.configModTime = info.ModTime()
 	}
-	d := noSpacingDelegate{DefaultDelegate: list.NewDefaultDelegate()}
-	d.ShowDescription = false
-	d.SetHeight(1)
-	m.feedsList.SetDelegate(d)
 	m.feedsList.SetShowTitle(false)
 	m.feedsList.SetShowStatusBar(false)
 	m.feedsList.SetShowPagination(true)
@@ -170,27 +270,57 @@ func NewModel() Model {
 			key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "help")),
 		}
 	}
-	ed := noSpacingDelegate{DefaultDelegate: list.NewDefaultDelegate()}
-	ed.ShowDescription = false
-	ed.SetHeight(1)
-	m.entriesList.SetDelegate(ed)
 	m.entriesList.SetShowTitle(false)
 	m.entriesList.SetShowStatusBar(false)
 	m.entriesList.SetShowPagination(true)
 	m.entriesList.SetShowHelp(false)
 	m.entriesList.AdditionalFullHelpKeys = m.feedsList.AdditionalFullHelpKeys
+	m.feedsList.DisableQuitKeybindings()
+	m.entriesList.DisableQuitKeybindings()
+	m.disableBuiltinKeys()
+	theme, err := resolveTheme(cfg.Theme, cfg, m.darkBackground)
+	if err != nil {
+		return Model{}, fmt.Errorf("%s: %w", configPath, err)
+	}
+	m.setTheme(theme)
+
+	return m, nil
+}
 
-	return m
+// disableBuiltinKeys stops the lists and the viewport from reacting to
+// keys on their own, navigation goes through the keymap instead. The lists
+// keep "/" so the filter action can start filtering.
+func (m *Model) disableBuiltinKeys() {
+	for _, l := range []*list.Model{&m.feedsList, &m.entriesList} {
+		l.KeyMap.CursorUp = key.NewBinding()
+		l.KeyMap.CursorDown = key.NewBinding()
+		l.KeyMap.PrevPage = key.NewBinding()
+		l.KeyMap.NextPage = key.NewBinding()
+		l.KeyMap.GoToStart = key.NewBinding()
+		l.KeyMap.GoToEnd = key.NewBinding()
+		l.KeyMap.ClearFilter = key.NewBinding()
+		l.KeyMap.ShowFullHelp = key.NewBinding()
+		l.KeyMap.CloseFullHelp = key.NewBinding()
+	}
+	m.viewport.KeyMap = viewport.KeyMap{}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(
+	cmds := []tea.Cmd{
 		m.loadFeeds,
 		m.loadShowArticleView,
+		m.loadLayout,
 		m.loadShowEntryDates,
+		loadCommandHistory,
 		m.spinner.Tick,
-		m.startBackgroundSync,
-	)
+		m.scheduleRefresh(),
+		m.watchConfig(),
+		m.purgeTrash,
+	}
+	if m.cfg.Refresh.OnStartup {
+		cmds = append(cmds, m.startBackgroundSync)
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m *Model) recalcPaneDimensions() {
@@ -209,22 +339,24 @@ func (m *Model) recalcPaneDimensions() {
 	// For 2 panes: fw + ew = w (where each includes 2 border chars)
 	// So we need to subtract border chars from the window width for content calculations.
 	
+	feedsRatio, entriesRatio := m.ratios()
+	layout := m.currentLayout()
 	var feedsWidth, entriesWidth, contentWidth int
-	if m.showArticleView {
+	if m.showArticleView && layout == config.LayoutColumns {
 		// 3 panes: each has 2 border chars, total 6 border chars
 		// Total content space = w - 6 (all borders)
 		availableWidth := w - 6
-		feedsWidth = int(float64(availableWidth) * 0.2)
-		if feedsWidth < 20 {
-			feedsWidth = 20
+		feedsWidth = int(float64(availableWidth) * feedsRatio)
+		if feedsWidth < minFeedsWidth {
+			feedsWidth = minFeedsWidth
 		}
-		entriesWidth = int(float64(availableWidth) * 0.25)
-		if entriesWidth < 25 {
-			entriesWidth = 25
+		entriesWidth = int(float64(availableWidth) * entriesRatio)
+		if entriesWidth < minEntriesWidth {
+			entriesWidth = minEntriesWidth
 		}
 		contentWidth = availableWidth - feedsWidth - entriesWidth
-		if contentWidth < 30 {
-			contentWidth = 30
+		if contentWidth < minContentWidth {
+			contentWidth = minContentWidth
 			// Adjust other panes if needed
 			remaining := availableWidth - contentWidth
 			if feedsWidth + entriesWidth > remaining {
@@ -236,31 +368,53 @@ func (m *Model) recalcPaneDimensions() {
 		// 2 panes: each has 2 border chars, total 4 border chars
 		// Total content space = w - 4 (all borders)
 		availableWidth := w - 4
-		feedsWidth = int(float64(availableWidth) * 0.2)
-		if feedsWidth < 20 {
-			feedsWidth = 20
+		feedsWidth = int(float64(availableWidth) * feedsRatio)
+		if feedsWidth < minFeedsWidth {
+			feedsWidth = minFeedsWidth
 		}
 		entriesWidth = availableWidth - feedsWidth
-		if entriesWidth < 25 {
-			entriesWidth = 25
+		if entriesWidth < minEntriesWidth {
+			entriesWidth = minEntriesWidth
 			feedsWidth = availableWidth - entriesWidth
 		}
 		contentWidth = 0
+		if m.showArticleView {
+			// Stacked, the article is as wide as the articles list
+			contentWidth = entriesWidth
+		}
+	}
+
+	// A single pane takes the whole width, the others aren't shown
+	if m.singlePane() {
+		feedsWidth, entriesWidth, contentWidth = w-2, w-2, w-2
 	}
 
 	paneHeight := h - 3
+	entriesHeight, contentHeight := paneHeight, paneHeight
+	if m.showArticleView && layout == config.LayoutStacked && !m.singlePane() {
+		// Both have their own borders
+		entriesHeight = (paneHeight - 2) * 2 / 5
+		contentHeight = paneHeight - 2 - entriesHeight
+	}
+	if m.reader {
+		// No borders, glamour wraps 4 columns narrower than the pane
+		contentWidth = min(m.cfg.Article.MaxWidth+4, w)
+		contentHeight = paneHeight + 2
+	}
 
 	m.paneHeight = paneHeight
 	m.feedsWidth = feedsWidth
 	m.entriesWidth = entriesWidth
 	m.contentWidth = contentWidth
+	m.entriesHeight = entriesHeight
+	m.contentHeight = contentHeight
 
 	// When using noSpacingDelegate (Spacing()=0), the bubbles list adds MarginTop(1) to
 	// pagination, making the list output 1 line taller. Reduce both list heights by 1 to compensate.
 	m.feedsList.SetSize(feedsWidth, paneHeight-2)
-	m.entriesList.SetSize(entriesWidth, paneHeight-2)
+	m.entriesList.SetSize(entriesWidth, entriesHeight-2)
 	m.viewport.Width = contentWidth
-	m.viewport.Height = paneHeight - 1
+	m.viewport.Height = contentHeight - 1
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -271,31 +425,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.recalcPaneDimensions()
 		m.textInput.Width = msg.Width - 10
 		m.filePicker.Height = msg.Height - 5
-
-		// Update renderer
-		if m.renderer == nil || m.width != msg.Width {
-			cw := m.contentWidth
-			if cw < 30 {
-				cw = 30
-			}
-			r, _ := glamour.NewTermRenderer(
-				glamour.WithStylePath("dark"),
-				glamour.WithWordWrap(cw-4),
-				glamour.WithEmoji(),
-			)
-			m.renderer = r
-		}
-		return m, nil
+		// Wrap the article at the new width
+		cmd = m.relayout()
+		return m, cmd
 
 	case tea.KeyMsg:
 		m.statusMsg = "" // Clear status message on any keypress
 		isFiltering := (m.feedsList.FilterState() == list.Filtering) ||
 			(m.entriesList.FilterState() == list.Filtering)
 
-		if msg.String() == "?" && m.state != stateHelp && m.state != stateAddingFeed && !isFiltering {
+		if key.Matches(msg, m.keys.binding("help").Binding) && m.state != stateHelp && m.state != stateAddingFeed && m.state != stateCommand && m.state != stateConfirm && m.state != stateLinkHint && m.state != stateSearch && !isFiltering {
 			m.previousState = m.state
 			m.state = stateHelp
 			return m, nil
@@ -303,135 +444,29 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch m.state {
 		case stateHelp:
-			if msg.String() == "q" || msg.String() == "esc" || msg.String() == "backspace" || msg.String() == "?" {
+			if msg.String() == "q" || msg.String() == "esc" || msg.String() == "backspace" || key.Matches(msg, m.keys.binding("help").Binding) {
 				m.state = m.previousState
 				return m, nil
 			}
 			return m, nil
 
 		case stateMain:
-			switch msg.String() {
-			case "q":
-				return m, tea.Quit
-			case "enter":
-				if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
-					openBrowser(i.entry.Link)
-				}
-				return m, nil
-			case "tab", "right":
-				numPanes := 3
-				if !m.showArticleView {
-					numPanes = 2
-				}
-				m.activePane = state((int(m.activePane) + 1) % numPanes)
-				return m, nil
-			case "shift+tab", "left":
-				numPanes := 3
-				if !m.showArticleView {
-					numPanes = 2
-				}
-				m.activePane = state((int(m.activePane) - 1 + numPanes) % numPanes)
-				return m, nil
-			case "a":
-				m.state = stateAddingFeed
-				m.textInput.Focus()
-				return m, nil
-			case "i":
-				m.state = stateImportingOPML
-				return m, m.filePicker.Init()
-			case "e":
-				return m, m.exportOPML
-			case "v":
-				m.showFeedInfo = !m.showFeedInfo
-				return m, nil
-			case "t":
-				m.showArticleView = !m.showArticleView
-				if !m.showArticleView && m.activePane == paneContent {
-					m.activePane = paneEntries
-				}
-				m.recalcPaneDimensions()
-				return m, m.saveShowArticleView(m.showArticleView)
-			case "d":
-				m.showEntryDates = !m.showEntryDates
-				// Refresh entries list to update titles with dates
-				if m.currentFeed.ID != 0 {
-					return m, tea.Batch(m.loadEntries(m.currentFeed), m.saveShowEntryDates(m.showEntryDates))
-				}
-				return m, m.saveShowEntryDates(m.showEntryDates)
-			case "r":
-				return m, m.refreshAllFeeds()
-			}
+			return m.handleMainKey(msg)
 
-			// Delegate to active pane
-			var cmd tea.Cmd
-			switch m.activePane {
-			case paneFeeds:
-				if m.feedsList.FilterState() == list.Filtering {
-					m.feedsList, cmd = m.feedsList.Update(msg)
-					return m, cmd
-				}
-				switch msg.String() {
-				case "up", "down", "j", "k":
-					m.feedsList, cmd = m.feedsList.Update(msg)
-					if i, ok := m.feedsList.SelectedItem().(feedItem); ok {
-						m.currentFeed = i.feed
-						return m, tea.Batch(cmd, m.loadEntries(i.feed))
-					}
-					return m, cmd
-				case "alt+up", "alt+k":
-					idx := m.feedsList.Index()
-					if idx > 0 {
-						itemA := m.feedsList.Items()[idx].(feedItem)
-						itemB := m.feedsList.Items()[idx-1].(feedItem)
-						posA, posB := itemA.feed.Position, itemB.feed.Position
-						if posA == posB {
-							posA, posB = idx, idx-1
-						}
-						db.SwapFeedPositions(int(itemA.feed.ID), posA, int(itemB.feed.ID), posB)
-						return m, m.loadFeedsWithIndex(idx - 1)
-					}
-				case "alt+down", "alt+j":
-					idx := m.feedsList.Index()
-					if idx < len(m.feedsList.Items())-1 {
-						itemA := m.feedsList.Items()[idx].(feedItem)
-						itemB := m.feedsList.Items()[idx+1].(feedItem)
-						posA, posB := itemA.feed.Position, itemB.feed.Position
-						if posA == posB {
-							posA, posB = idx, idx+1
-						}
-						db.SwapFeedPositions(int(itemA.feed.ID), posA, int(itemB.feed.ID), posB)
-						return m, m.loadFeedsWithIndex(idx + 1)
-					}
-				case "D":
-					if i, ok := m.feedsList.SelectedItem().(feedItem); ok {
-						return m, m.deleteFeed(i.feed.ID)
-					}
-				}
-				m.feedsList, cmd = m.feedsList.Update(msg)
-				return m, cmd
+		case stateCommand:
+			return m.updateCommandLine(msg)
 
-			case paneEntries:
-				if m.entriesList.FilterState() == list.Filtering {
-					m.entriesList, cmd = m.entriesList.Update(msg)
-					return m, cmd
-				}
-				switch msg.String() {
-				case "up", "down", "j", "k":
-					m.entriesList, cmd = m.entriesList.Update(msg)
-					if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
-						return m, tea.Batch(cmd, m.viewEntry(i.entry))
-					}
-					return m, cmd
-				case "r":
-					return m, m.refreshCurrentFeed()
-				}
-				m.entriesList, cmd = m.entriesList.Update(msg)
-				return m, cmd
+		case stateConfirm:
+			return m.updateConfirm(msg)
 
-			case paneContent:
-				m.viewport, cmd = m.viewport.Update(msg)
-				return m, cmd
-			}
+		case stateOutput:
+			return m.updateOutput(msg)
+
+		case stateLinkHint:
+			return m.updateLinkHint(msg)
+
+		case stateSearch:
+			return m.updateSearch(msg)
 
 		case stateAddingFeed:
 			switch msg.String() {
@@ -466,26 +501,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.MouseMsg:
 		// No DocStyle padding, so no adjustment needed
 
+		// Dragging the border between two panes resizes them
+		if handled, cmd := m.updateDrag(msg); handled {
+			return m, cmd
+		}
+
 		// Handle Scrolling
 		if msg.Action == tea.MouseActionPress {
 			if msg.Button == tea.MouseButtonWheelUp || msg.Button == tea.MouseButtonWheelDown {
 				// Route scroll to the pane the mouse is currently over
-				if msg.X < m.feedsList.Width() {
+				switch m.paneAt(msg.X, msg.Y) {
+				case paneFeeds:
 					m.feedsList, cmd = m.feedsList.Update(msg)
-					return m, cmd
-				} else if !m.showArticleView || msg.X < m.feedsList.Width()+m.entriesList.Width()+2 {
+				case paneEntries:
 					m.entriesList, cmd = m.entriesList.Update(msg)
-					return m, cmd
-				} else {
+				default:
 					m.viewport, cmd = m.viewport.Update(msg)
-					return m, cmd
 				}
+				return m, cmd
 			}
 		}
 
 		// Handle Clicking
 		if msg.Type == tea.MouseLeft && msg.Action == tea.MouseActionRelease {
-			if msg.X < m.feedsList.Width() {
+			switch m.paneAt(msg.X, msg.Y) {
+			case paneFeeds:
 				m.activePane = paneFeeds
 				m.feedsList, cmd = m.feedsList.Update(msg)
 				if i, ok := m.feedsList.SelectedItem().(feedItem); ok {
@@ -493,14 +533,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, tea.Batch(cmd, m.loadEntries(i.feed))
 				}
 				return m, cmd
-			} else if !m.showArticleView || msg.X < m.feedsList.Width()+m.entriesList.Width()+2 {
+			case paneEntries:
 				m.activePane = paneEntries
 				m.entriesList, cmd = m.entriesList.Update(msg)
-				if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
-					return m, tea.Batch(cmd, m.viewEntry(i.entry))
-				}
-				return m, cmd
-			} else {
+				skipHeaders(&m.entriesList, true)
+				return m, tea.Batch(cmd, m.viewSelected())
+			default:
 				m.activePane = paneContent
 				m.viewport, cmd = m.viewport.Update(msg)
 				return m, cmd
@@ -521,46 +559,129 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.loadFeeds
 
 	case feedsMsg:
+		selected, hadSelection := m.feedsList.SelectedItem().(feedItem)
 		m.feedsList.SetItems(msg.items)
+		m.feedSort = msg.sort
 		if msg.index >= 0 && msg.index < len(msg.items) {
 			m.feedsList.Select(msg.index)
+		} else if hadSelection {
+			// Keep the cursor on the same feed when the order changed
+			for idx, it := range msg.items {
+				if fi, ok := it.(feedItem); ok && fi.feed.ID == selected.feed.ID {
+					m.feedsList.Select(idx)
+					break
+				}
+			}
 		}
 		m.loading = false
-		// Only auto-load entries for the first feed on the very first load.
-		// Subsequent reloads (from background sync) just update the list silently.
+		// Only restore the last session on the very first load. Subsequent
+		// reloads (from background sync) just update the list silently.
 		if !m.initialLoadDone && len(msg.items) > 0 {
 			m.initialLoadDone = true
-			if i, ok := m.feedsList.SelectedItem().(feedItem); ok {
-				m.currentFeed = i.feed
-				return m, m.loadEntries(i.feed)
-			}
+			return m, m.loadSession
 		}
 
+	case sessionMsg:
+		return m.restoreSession(db.Session(msg))
+
 	case entriesMsg:
-		items := make([]list.Item, len(msg.entries))
-		for i, e := range msg.entries {
-			items[i] = entryItem{entry: e, feedLastReadAt: msg.lastReadAt, showDates: m.showEntryDates}
+		// Drop entries of a feed that is no longer selected
+		if msg.feedID != m.currentFeed.ID {
+			return m, nil
 		}
-		m.entriesList.SetItems(items)
+		items := m.entryItems(msg)
 		m.loading = false
-		// Load content for the first entry automatically
-		if len(items) > 0 {
-			if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
-				return m, m.viewEntry(i.entry)
+		m.riverMore = msg.more
+		m.riverLoading = false
+		m.feedPrefs = msg.prefs
+		if msg.appendPage {
+			return m, m.entriesList.SetItems(append(m.entriesList.Items(), items...))
+		}
+		m.entriesList.SetItems(items)
+		if msg.selectID != 0 {
+			m.entriesList.ResetFilter()
+			m.entriesList.Select(0)
+			for idx, it := range items {
+				if e, ok := it.(entryItem); ok && e.entry.ID == msg.selectID {
+					m.entriesList.Select(idx)
+					break
+				}
 			}
 		}
+		skipHeaders(&m.entriesList, true)
+		// Load content for the selected entry automatically
+		if len(items) > 0 {
+			return m, m.viewSelected()
+		}
+
+	case unreadMsg:
+		return m.showUnread(msg)
+
+	case selectionMsg:
+		m.statusMsg = msg.status
+		if msg.undo != nil {
+			m.pushUndo(*msg.undo)
+		}
+		var cmds []tea.Cmd
+		if len(msg.readIDs) > 0 {
+			cmds = append(cmds, m.updateEntries(msg.readIDs, func(e *db.Entry) { e.Read = msg.read }))
+		}
+		if msg.reloadFeeds {
+			cmds = append(cmds, m.loadFeeds)
+		}
+		return m, tea.Batch(cmds...)
 
 	case contentMsg:
-		m.viewport.SetContent(string(msg))
+		m.content, m.links = msg.text, msg.links
+		m.pageTitle, m.pageURL, m.pageMD, m.pageHTML = msg.title, msg.url, msg.markdown, msg.html
+		if msg.rerender {
+			// Same article at another width, keep the place in it
+			offset := m.viewport.ScrollPercent()
+			m.setContent()
+			m.scrollToPercent(offset)
+			m.search.current = min(m.search.current, max(len(m.search.matches)-1, 0))
+			return m, nil
+		}
+		m.search = articleSearch{}
+		if msg.entryID == 0 || msg.entryID != m.shownEntryID {
+			// Another article, the one left opens where it was next time
+			cmds = append(cmds, m.saveReadingPosition())
+			position := msg.position
+			if m.jump != nil {
+				// Back or forward, which keep the history
+				if m.jump.entryID == msg.entryID {
+					position = m.jump.scroll
+				}
+				m.jump = nil
+			} else if msg.entryID != 0 {
+				m.visit()
+			}
+			m.setContent()
+			m.scrollToPercent(position)
+			m.shownEntryID, m.shownFeedID, m.shownPosition = msg.entryID, msg.feedID, msg.position
+			m.shownFeed = m.currentFeed
+		} else {
+			m.setContent()
+		}
 		m.loading = false
+		cmds = append(cmds, m.loadImages())
+
+	case imagesMsg:
+		m.setContent()
+		return m, nil
+
+	case layoutMsg:
+		m.feedsRatio, m.entriesRatio = msg.feeds, msg.entries
+		cmd = m.relayout()
+		return m, cmd
 
 	case showArticleViewMsg:
 		m.showArticleView = bool(msg)
-		m.recalcPaneDimensions()
 		if !m.showArticleView && m.activePane == paneContent {
 			m.activePane = paneEntries
 		}
-		return m, nil
+		cmd = m.relayout()
+		return m, cmd
 	case showEntryDatesMsg:
 		m.showEntryDates = bool(msg)
 		// Refresh entries list to update titles with dates
@@ -573,9 +694,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.statusMsg = string(msg)
 		m.loading = false
 
+	case pipeMsg:
+		m = m.showPipeOutput(msg)
+
+	case refreshTickMsg:
+		// Ticks scheduled before a config reload are stale
+		if int(msg) != m.refreshGen {
+			return m, nil
+		}
+		return m, tea.Batch(m.startBackgroundSync, m.scheduleRefresh())
+
+	case configChangedMsg:
+		m.configModTime = msg.modTime
+		if msg.err != nil {
+			m.statusMsg = ErrorStyle.Render("Config not reloaded: " + strings.ReplaceAll(msg.err.Error(), "\n", " "))
+			return m, m.watchConfig()
+		}
+		keys, err := newKeyMap(msg.cfg.Keys)
+		if err != nil {
+			m.statusMsg = ErrorStyle.Render("Config not reloaded: " + strings.ReplaceAll(err.Error(), "\n", " "))
+			return m, m.watchConfig()
+		}
+		m.keys = keys
+		m.statusMsg = "Config reloaded"
+		cmd := m.applyConfig(msg.cfg)
+		return m, tea.Batch(cmd, m.watchConfig())
+
+	case configUnchangedMsg:
+		return m, m.watchConfig()
+
+	case commandHistoryMsg:
+		m.commandHistory = msg
+		return m, nil
+
 	case errMsg:
 		// Display error in the content pane instead of crashing
-		m.viewport.SetContent(ErrorStyle.Render(fmt.Sprintf("Error: %v", msg)))
+		m.content, m.links = ErrorStyle.Render(fmt.Sprintf("Error: %v", msg)), nil
+		m.setContent()
 		m.loading = false
 
 	case spinner.TickMsg:
@@ -595,6 +750,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stateAddingFeed:
 		m.textInput, cmd = m.textInput.Update(msg)
 		cmds = append(cmds, cmd)
+	case stateCommand:
+		m.commandInput, cmd = m.commandInput.Update(msg)
+		cmds = append(cmds, cmd)
+	case stateSearch:
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		cmds = append(cmds, cmd)
 	case stateImportingOPML:
 		m.filePicker, cmd = m.filePicker.Update(msg)
 		cmds = append(cmds, cmd)
@@ -608,6 +769,14 @@ func (m Model) View() string {
 		return DocStyle.Render(m.helpView())
 	}
 
+	if m.state == stateConfirm {
+		return m.confirmView()
+	}
+
+	if m.state == stateOutput {
+		return m.outputView()
+	}
+
 	if m.state == stateAddingFeed {
 		return DocStyle.Render(TitleStyle.Render("Add Feed") + "\n\n" +
 			"Enter URL:\n\n" + m.textInput.View() + "\n\n(esc to cancel)")
@@ -649,27 +818,36 @@ func (m Model) View() string {
 	if cw <= 0 {
 		cw = 40
 	}
+	// The stacked layout splits the height between articles and article
+	eh, ch := h, h
+	if m.entriesHeight > 0 && m.contentHeight > 0 {
+		eh, ch = m.entriesHeight, m.contentHeight
+	}
 
-	feedsTitle := m.feedsList.Styles.Title.Copy().MarginLeft(2).Render("Feeds")
+	feedsTitle := m.feedsList.Styles.Title.Copy().MarginLeft(2).Render("Feeds") + sortLabel(string(m.feedSort))
+	entriesSort := m.entriesLabel()
 	feedsListStr := m.feedsList.View()
 	feedsContent := lipgloss.JoinVertical(lipgloss.Left, feedsTitle, feedsListStr)
 	feedsView := feedsStyle.Width(fw).Height(h).Render(feedsContent)
 
 	var entriesTitle string
-	if m.currentFeed.ID != 0 {
+	if isVirtualFeed(m.currentFeed) {
+		title := runewidth.Truncate(m.currentFeed.Title, ew-6-lipgloss.Width(entriesSort), "...")
+		entriesTitle = m.entriesList.Styles.Title.Copy().MarginLeft(2).Render(title) + entriesSort
+	} else if m.currentFeed.ID != 0 {
 		osc8Start := "\x1b]8;;" + m.currentFeed.URL + "\x1b\\"
 		osc8End := "\x1b]8;;\x1b\\"
 		// Truncate the visible text to avoid overflow, then wrap in OSC 8
-		title := runewidth.Truncate(m.currentFeed.Title, ew-6, "...")
-		entriesTitle = m.entriesList.Styles.Title.Copy().MarginLeft(2).Render(osc8Start + title + osc8End)
+		title := runewidth.Truncate(m.currentFeed.Title, ew-6-lipgloss.Width(entriesSort), "...")
+		entriesTitle = m.entriesList.Styles.Title.Copy().MarginLeft(2).Render(osc8Start+title+osc8End) + entriesSort
 	} else {
 		entriesTitle = m.entriesList.Styles.Title.Copy().MarginLeft(2).Render("Articles")
 	}
 
 	var entriesView string
 	if m.showFeedInfo && m.currentFeed.ID != 0 {
-		infoStyle := lipgloss.NewStyle().Padding(1, 2).Foreground(lipgloss.Color("252"))
-		labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Bold(true)
+		infoStyle := InfoStyle
+		labelStyle := LabelStyle
 
 		desc := m.currentFeed.Description
 		if desc == "" {
@@ -689,15 +867,18 @@ func (m Model) View() string {
 			labelStyle.Render("Added:"),
 			m.currentFeed.CreatedAt.Format("2006-01-02 15:04"),
 		)
-		entriesView = entriesStyle.Width(ew).Height(h).Render(lipgloss.JoinVertical(lipgloss.Left, entriesTitle, infoStyle.Render(info)))
+		entriesView = entriesStyle.Width(ew).Height(eh).Render(lipgloss.JoinVertical(lipgloss.Left, entriesTitle, infoStyle.Render(info)))
 	} else {
-		entriesView = entriesStyle.Width(ew).Height(h).Render(lipgloss.JoinVertical(lipgloss.Left, entriesTitle, m.entriesList.View()))
+		entriesView = entriesStyle.Width(ew).Height(eh).Render(lipgloss.JoinVertical(lipgloss.Left, entriesTitle, m.entriesList.View()))
 	}
 
-	var mainView string
-	if m.showArticleView {
+	var contentView string
+	if m.showArticleView || m.reader {
 		var contentTitle string
-		if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
+		if m.pageURL != "" {
+			title := runewidth.Truncate(m.pageTitle, cw-6, "...")
+			contentTitle = "\x1b]8;;" + m.pageURL + "\x1b\\" + title + "\x1b]8;;\x1b\\"
+		} else if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
 			// Make the Article Title itself clickable
 			osc8Start := "\x1b]8;;" + i.entry.Link + "\x1b\\"
 			osc8End := "\x1b]8;;\x1b\\"
@@ -705,24 +886,55 @@ func (m Model) View() string {
 			contentTitle = osc8Start + title + osc8End
 		}
 		contentHeader := TitleStyle.Copy().PaddingLeft(2).MaxHeight(1).Render(contentTitle)
-		contentView := contentStyle.Width(cw).Height(h).Render(lipgloss.JoinVertical(lipgloss.Left, contentHeader, m.viewport.View()))
-		mainView = lipgloss.JoinHorizontal(lipgloss.Top, feedsView, entriesView, contentView)
-	} else {
-		mainView = lipgloss.JoinHorizontal(lipgloss.Top, feedsView, entriesView)
+		contentView = contentStyle.Width(cw).Height(ch).Render(lipgloss.JoinVertical(lipgloss.Left, contentHeader, m.viewport.View()))
+		if m.reader {
+			// Borderless, in the middle of the window
+			contentView = lipgloss.NewStyle().Width(cw).Height(ch).Render(lipgloss.JoinVertical(lipgloss.Left, contentHeader, m.viewport.View()))
+			contentView = lipgloss.PlaceHorizontal(m.width, lipgloss.Center, contentView)
+		}
 	}
 
 	// Status Bar: pill on left, status in middle, help hint on right
 	// Width should match the mainView width
 	// Borders don't overlap, so total = sum of all pane widths
 	// Each pane width already includes its borders (Width() adds 2 chars)
-	totalWidth := fw + ew
-	if m.showArticleView {
+	var mainView string
+	var totalWidth int
+	switch {
+	case m.reader:
+		mainView, totalWidth = contentView, m.width
+	case m.singlePane():
+		// Only the active pane is shown, over the whole width
+		mainView, totalWidth = feedsView, fw
+		switch m.activePane {
+		case paneEntries:
+			mainView, totalWidth = entriesView, ew
+		case paneContent:
+			mainView, totalWidth = contentView, cw
+		}
+	case m.showArticleView && m.currentLayout() == config.LayoutStacked:
+		// The article sits below the articles list
+		mainView = lipgloss.JoinHorizontal(lipgloss.Top, feedsView, lipgloss.JoinVertical(lipgloss.Left, entriesView, contentView))
+		totalWidth = fw + ew
+	case m.showArticleView:
+		mainView = lipgloss.JoinHorizontal(lipgloss.Top, feedsView, entriesView, contentView)
 		totalWidth = fw + ew + cw
+	default:
+		mainView = lipgloss.JoinHorizontal(lipgloss.Top, feedsView, entriesView)
+		totalWidth = fw + ew
 	}
-	pill := StatusPillStyle.Render("Lazy RSS")
+
+	pillText := "Lazy RSS"
+	if m.profile != "" {
+		pillText += " · " + m.profile
+	}
+	pill := StatusPillStyle.Render(pillText)
 	pillWidth := lipgloss.Width(pill)
 
 	helpHint := StatusHelpStyle.Render("? help")
+	if m.reader {
+		helpHint = StatusTextStyle.Render(m.readerProgress()+" ") + helpHint
+	}
 	helpWidth := lipgloss.Width(helpHint)
 
 	midText := ""
@@ -732,14 +944,29 @@ func (m Model) View() string {
 		midText = m.spinner.View() + " Syncing feeds..."
 	} else if m.statusMsg != "" {
 		midText = m.statusMsg
+	} else if m.state == stateLinkHint {
+		midText = m.hintPrompt()
+	} else if m.search.query != "" {
+		midText = m.searchStatus()
+	} else if m.count > 0 || m.pendingKeys != "" {
+		// Echo an unfinished count or key sequence, like vim's showcmd
+		if m.count > 0 {
+			midText = fmt.Sprint(m.count)
+		}
+		midText += strings.ReplaceAll(m.pendingKeys, " ", "")
 	}
 	midWidth := totalWidth - pillWidth - helpWidth
 	if midWidth < 0 {
 		midWidth = 0
 	}
-	mid := StatusTextStyle.Width(midWidth).Render(midText)
+	mid := StatusTextStyle.Width(midWidth).MaxHeight(1).Render(midText)
 
 	statusBar := lipgloss.JoinHorizontal(lipgloss.Top, pill, mid, helpHint)
+	if m.state == stateCommand {
+		statusBar = m.commandLineView(totalWidth)
+	} else if m.state == stateSearch {
+		statusBar = lipgloss.NewStyle().MaxWidth(totalWidth).Render(m.searchInput.View())
+	}
 	fullContent := lipgloss.JoinVertical(lipgloss.Left, mainView, statusBar)
 
 	// No horizontal padding - use full width
@@ -750,6 +977,7 @@ func (m Model) View() string {
 type feedsMsg struct {
 	items []list.Item
 	index int
+	sort  db.FeedSort
 }
 type backgroundSyncMsg struct {
 	feeds []db.Feed
@@ -758,24 +986,62 @@ type feedSyncedMsg struct {
 	feedID int64
 }
 type entriesMsg struct {
+	feedID     int64
 	entries    []db.Entry
-	lastReadAt time.Time
+	more       bool // more pages can be loaded
+	appendPage bool // entries follow the ones already listed
+	selectID   int64 // entry to select instead of the first one
+	prefs      db.FeedPrefs
+}
+// contentMsg carries a rendered article, with its links left as tokens
+type contentMsg struct {
+	text  string
+	links []articleLink
+	// title, url, Markdown and HTML of a page opened from a link, empty
+	// for entries
+	title    string
+	url      string
+	markdown string
+	html     string
+	// rerender is set when the article shown was rendered again, for
+	// another width or theme
+	rerender bool
+	// entryID is the entry shown, feedID its feed and position how far it
+	// was read before, see saveReadingPosition
+	entryID  int64
+	feedID   int64
+	position float64
 }
-type contentMsg string
 type exportMsg string
 type showArticleViewMsg bool
 type showEntryDatesMsg bool
+type refreshTickMsg int
+type configChangedMsg struct {
+	cfg     *config.Config
+	modTime time.Time
+	err     error
+}
+type configUnchangedMsg struct{}
 
 func (m Model) loadFeeds() tea.Msg {
-	feeds, err := db.GetFeeds()
+	sort, err := db.GetFeedSort()
+	if err != nil {
+		return errMsg(err)
+	}
+	feeds, err := db.GetFeeds(sort)
 	if err != nil {
 		return errMsg(err)
 	}
-	items := make([]list.Item, len(feeds))
-	for i, f := range feeds {
-		items[i] = feedItem{feed: f}
+	unread, err := db.CountUnread()
+	if err != nil {
+		return errMsg(err)
 	}
-	return feedsMsg{items: items, index: -1}
+	items := make([]list.Item, 0, len(feeds)+1)
+	items = append(items, feedItem{feed: riverFeed(unread)}, feedItem{feed: recentFeed()})
+	for _, f := range feeds {
+		items = append(items, feedItem{feed: f, selection: m.selectedFeeds})
+	}
+	return feedsMsg{items: items, index: -1, sort: sort}
 }
 
 func (m Model) loadFeedsWithIndex(index int) tea.Cmd {
@@ -790,15 +1056,22 @@ func (m Model) loadFeedsWithIndex(index int) tea.Cmd {
 }
 
 func (m Model) loadEntries(feed db.Feed) tea.Cmd {
+	switch feed.ID {
+	case riverFeedID:
+		return m.loadRiverPage(nil)
+	case recentFeedID:
+		return m.loadRecent
+	}
 	return func() tea.Msg {
-		entries, err := db.GetEntries(feed.ID)
+		prefs, err := db.GetFeedPrefs(feed.ID)
 		if err != nil {
 			return errMsg(err)
 		}
-		// We capture the LastReadAt BEFORE we update it in the DB
-		lastReadAt := feed.LastReadAt
-		db.MarkFeedAsRead(feed.ID)
-		return entriesMsg{entries: entries, lastReadAt: lastReadAt}
+		entries, err := db.GetEntries(prefs.Query(feed.ID, m.dates.now()))
+		if err != nil {
+			return errMsg(err)
+		}
+		return entriesMsg{feedID: feed.ID, entries: entries, prefs: prefs}
 	}
 }
 
@@ -820,21 +1093,69 @@ func (m Model) addFeed(url string) tea.Cmd {
 	}
 }
 
-func (m Model) deleteFeed(id int64) tea.Cmd {
-	return func() tea.Msg {
-		err := db.DeleteFeed(id)
-		if err != nil {
-			return errMsg(err)
+// scheduleRefresh arms the next periodic sync. Bumping refreshGen
+// invalidates ticks that were armed with an older interval.
+func (m Model) scheduleRefresh() tea.Cmd {
+	if m.cfg.Refresh.Interval <= 0 {
+		return nil
+	}
+	gen := m.refreshGen
+	return tea.Tick(m.cfg.Refresh.Interval, func(time.Time) tea.Msg {
+		return refreshTickMsg(gen)
+	})
+}
+
+// watchConfig polls the config file for changes
+func (m Model) watchConfig() tea.Cmd {
+	path, modTime := m.configPath, m.configModTime
+	return tea.Tick(2*time.Second, func(time.Time) tea.Msg {
+		info, err := os.Stat(path)
+		if err != nil || info.ModTime().Equal(modTime) {
+			return configUnchangedMsg{}
 		}
-		return m.loadFeeds()
+		cfg, err := config.Load(path)
+		return configChangedMsg{cfg: cfg, modTime: info.ModTime(), err: err}
+	})
+}
+
+func (m *Model) applyConfig(cfg *config.Config) tea.Cmd {
+	m.cfg = cfg
+	m.layoutMode = cfg.Layout.Mode
+	m.dates = newDateFormat(cfg.Dates)
+	// Date formats and themes may have changed
+	m.renderCache.clear()
+	if p := imageProtocol(cfg.Article.Images); p != m.imageProtocol {
+		m.imageProtocol = p
+		m.images.clearLines()
+	}
+	if err := rss.Configure(cfg.HTTP.Timeout, cfg.HTTP.UserAgent, cfg.HTTP.Proxy); err != nil {
+		m.statusMsg = ErrorStyle.Render("Config reloaded, HTTP settings not applied: " + err.Error())
+	}
+	m.recalcPaneDimensions()
+	if t, err := resolveTheme(cfg.Theme, cfg, m.darkBackground); err != nil {
+		m.statusMsg = ErrorStyle.Render("Config reloaded, theme not applied: " + err.Error())
+	} else {
+		m.setTheme(t)
+	}
+	m.refreshGen++
+	cmds := []tea.Cmd{m.scheduleRefresh()}
+	if m.currentFeed.ID != 0 {
+		cmds = append(cmds, m.loadEntries(m.currentFeed))
 	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) startBackgroundSync() tea.Msg {
-	feeds, err := db.GetFeeds()
+	all, err := db.GetFeeds(db.FeedSortManual)
 	if err != nil {
 		return errMsg(err)
 	}
+	var feeds []db.Feed
+	for _, f := range all {
+		if !f.Paused {
+			feeds = append(feeds, f)
+		}
+	}
 	return backgroundSyncMsg{feeds: feeds}
 }
 
@@ -853,6 +1174,9 @@ func (m Model) refreshAllFeeds() tea.Cmd {
 }
 
 func (m Model) refreshCurrentFeed() tea.Cmd {
+	if isVirtualFeed(m.currentFeed) {
+		return m.refreshAllFeeds()
+	}
 	return func() tea.Msg {
 		err := rss.SyncFeed(m.currentFeed.ID, m.currentFeed.URL)
 		if err != nil {
@@ -925,65 +1249,80 @@ func (m Model) saveShowEntryDates(show bool) tea.Cmd {
 }
 
 func (m Model) exportOPML() tea.Msg {
-	feeds, err := db.GetFeeds()
-	if err != nil {
-		return errMsg(err)
-	}
+	home, _ := os.UserHomeDir()
+	return m.exportOPMLTo(filepath.Join(home, "Downloads", "feeds_export.opml"))()
+}
 
-	var outlines []rss.Outline
-	for _, f := range feeds {
-		outlines = append(outlines, rss.Outline{
-			Text:   f.Title,
-			Title:  f.Title,
-			Type:   "rss",
-			XMLURL: f.URL,
-		})
-	}
+func (m Model) exportOPMLTo(exportPath string) tea.Cmd {
+	return func() tea.Msg {
+		feeds, err := db.GetFeeds(db.FeedSortManual)
+		if err != nil {
+			return errMsg(err)
+		}
 
-	data, err := rss.GenerateOPML(outlines)
-	if err != nil {
-		return errMsg(err)
-	}
+		var outlines []rss.Outline
+		for _, f := range feeds {
+			outlines = append(outlines, rss.Outline{
+				Text:   f.Title,
+				Title:  f.Title,
+				Type:   "rss",
+				XMLURL: f.URL,
+			})
+		}
 
-	home, _ := os.UserHomeDir()
-	exportPath := filepath.Join(home, "Downloads", "feeds_export.opml")
-	err = os.WriteFile(exportPath, data, 0644)
-	if err != nil {
-		return errMsg(err)
+		data, err := rss.GenerateOPML(outlines)
+		if err != nil {
+			return errMsg(err)
+		}
+
+		err = os.WriteFile(exportPath, data, 0644)
+		if err != nil {
+			return errMsg(err)
+		}
+
+		return exportMsg(fmt.Sprintf("Exported to %s", exportPath))
 	}
+}
 
-	return exportMsg(fmt.Sprintf("Exported to %s", exportPath))
+// setTheme applies t to the package styles and to the styles owned by the
+// bubbles components, and rebuilds the article renderer.
+func (m *Model) setTheme(t Theme) {
+	m.theme = t
+	applyTheme(t)
+	m.spinner.Style = lipgloss.NewStyle().Foreground(t.Accent)
+	for _, l := range []*list.Model{&m.feedsList, &m.entriesList} {
+		l.Styles.Title = PaneTitleStyle
+		l.Styles.FilterCursor = lipgloss.NewStyle().Foreground(t.Accent)
+		d := noSpacingDelegate{DefaultDelegate: list.NewDefaultDelegate()}
+		d.ShowDescription = false
+		d.SetHeight(1)
+		d.Styles.SelectedTitle = d.Styles.SelectedTitle.Foreground(t.Accent).BorderForeground(t.Accent)
+		l.SetDelegate(d)
+	}
+	m.rebuildRenderer()
 }
 
-func (m Model) renderMarkdown(md string) string {
+// renderMarkdown renders md for the content pane. Links and images are left
+// as tokens that expandLinks turns into hyperlinks, they are appended to
+// links in the order they appear.
+func (m Model) renderMarkdown(md string, links *[]articleLink) string {
 	if m.renderer == nil {
 		return md
 	}
 
 	// Pre-process: convert linked images [![alt](img-url)](link-url)
 	// into simple images ![alt](link-url) so the main regex can handle them.
-	reLinkedImg := regexp.MustCompile(`\[!\[([^\]]*)\]\([^)]+\)\]\(([^)]+)\)`)
-	md = reLinkedImg.ReplaceAllString(md, "![$1]($2)")
+	md = linkedImageRe.ReplaceAllString(md, "![$1]($2)")
 
 	// Tokenize links and images to avoid showing URLs.
-	// The regex captures: 1: optional '\', 2: optional '!', 3: link text/alt, 4: url
-	re := regexp.MustCompile(`(\\)?(!)?\[([^\]]*)\]\(\s*([^\s\)]+)(?:\s+["'][^"']*["'])?\s*\)`)
-	type linkInfo struct {
-		text    string
-		url     string
-		isImage bool
-	}
-	links := make(map[string]linkInfo)
-	tokenCount := 0
+	added := len(*links)
 
 	// Pre-process markdown to replace links with unique tokens
-	processedMD := re.ReplaceAllStringFunc(md, func(match string) string {
-		submatch := re.FindStringSubmatch(match)
+	processedMD := linkRe.ReplaceAllStringFunc(md, func(match string) string {
+		submatch := linkRe.FindStringSubmatch(match)
 		if len(submatch) < 5 {
 			return match
 		}
-		// Use a token that Glamour won't interpret as Markdown
-		token := fmt.Sprintf("GLAMOURTOKEN%dURL", tokenCount)
 		text := submatch[3]
 		isImage := submatch[2] == "!"
 		if text == "" && isImage {
@@ -992,159 +1331,141 @@ func (m Model) renderMarkdown(md string) string {
 			text = "Link"
 		}
 
-		links[token] = linkInfo{
-			isImage: isImage,
-			text:    text,
-			url:     submatch[4],
-		}
-		tokenCount++
-		return token
+		*links = append(*links, articleLink{
+			image: isImage,
+			text:  text,
+			url:   submatch[4],
+		})
+		return linkToken(len(*links) - 1)
 	})
 
 	// Render with glamour
+	m.renderCache.rendering.Lock()
 	rendered, err := m.renderer.Render(processedMD)
+	m.renderCache.rendering.Unlock()
 	if err != nil {
+		*links = (*links)[:added]
 		return md
 	}
-
-	// Post-process to insert OSC 8 links
-	for token, info := range links {
-		displayText := info.text
-		if info.isImage {
-			displayText = "[img] " + displayText
-		}
-
-		// Style the link text (blue and underlined)
-		styledText := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("12")).
-			Underline(true).
-			Render(displayText)
-
-		// Create OSC 8 hyperlink
-		osc8 := fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\", info.url, styledText)
-		rendered = strings.ReplaceAll(rendered, token, osc8)
-	}
-
 	return strings.TrimSpace(rendered)
 }
 
 func (m Model) viewEntry(e db.Entry) tea.Cmd {
 	return func() tea.Msg {
 		db.MarkAsRead(e.ID)
+		msg := m.renderEntry(e)
+		msg.entryID, msg.feedID = e.ID, e.FeedID
+		msg.position, _ = db.GetReadingPosition(e.ID)
+		return msg
+	}
+}
 
-		// Build metadata (published date + link), each on its own line, indented
-		metaStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).PaddingLeft(2)
-		var metaLines []string
-		if !e.PublishedAt.IsZero() {
-			metaLines = append(metaLines, metaStyle.Render(e.PublishedAt.Format("Mon, 02 Jan 2006 15:04")))
-		}
-		if e.Link != "" {
-			linkOsc := fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\",
-				e.Link,
-				lipgloss.NewStyle().Foreground(lipgloss.Color("12")).Underline(true).Render(e.Link))
-			metaLines = append(metaLines, metaStyle.Render(linkOsc))
-		}
+// renderEntry renders e for the content pane, or takes it from the cache
+func (m Model) renderEntry(e db.Entry) contentMsg {
+	var date string
+	if !e.PublishedAt.IsZero() {
+		date = m.dates.articleDate(e.PublishedAt, m.dates.now())
+	}
+	key := renderKey{entryID: e.ID, width: m.rendererWidth, theme: m.theme.Name, date: date}
+	if msg, ok := m.renderCache.get(key, e); ok {
+		return msg
+	}
+	msg := m.renderArticle(e, date)
+	m.renderCache.put(key, e, msg)
+	return msg
+}
 
-		var out string
-		if len(metaLines) > 0 {
-			out += "\n" + strings.Join(metaLines, "\n") + "\n\n"
-		}
+func (m Model) renderArticle(e db.Entry, date string) contentMsg {
+	// Build metadata (published date + link), each on its own line, indented
+	metaStyle := MetaStyle
+	var metaLines []string
+	if date != "" {
+		metaLines = append(metaLines, metaStyle.Render(date))
+	}
+	if e.Link != "" {
+		linkOsc := fmt.Sprintf("\x1b]8;;%s\x1b\\%s\x1b]8;;\x1b\\",
+			e.Link,
+			LinkStyle.Render(e.Link))
+		metaLines = append(metaLines, metaStyle.Render(linkOsc))
+	}
 
-		// Convert HTML to Markdown for both description and content
-		descMD, _ := htmltomarkdown.ConvertString(e.Description)
-		contentMD, _ := htmltomarkdown.ConvertString(e.Content)
+	var out string
+	if len(metaLines) > 0 {
+		out += "\n" + strings.Join(metaLines, "\n") + "\n\n"
+	}
 
-		if descMD != "" {
-			renderedDesc := m.renderMarkdown(descMD)
-			if renderedDesc != "" && renderedDesc != "\n" {
-				out += DescriptionReadingStyle.Render(renderedDesc)
-			}
+	// Convert HTML to Markdown for both description and content,
+	// relative links point to the article's site
+	var opts []converter.ConvertOptionFunc
+	if e.Link != "" {
+		opts = append(opts, converter.WithDomain(e.Link))
+	}
+	descMD, _ := htmltomarkdown.ConvertString(e.Description, opts...)
+	contentMD, _ := htmltomarkdown.ConvertString(e.Content, opts...)
+
+	var links []articleLink
+	if descMD != "" {
+		renderedDesc := m.renderMarkdown(descMD, &links)
+		if renderedDesc != "" && renderedDesc != "\n" {
+			out += DescriptionReadingStyle.Render(renderedDesc)
 		}
+	}
 
-		if contentMD != "" && contentMD != descMD {
-			renderedContent := m.renderMarkdown(contentMD)
-			if renderedContent != "" && renderedContent != "\n" {
-				if out != "" {
-					out += "\n\n"
-				}
-				out += renderedContent
+	if contentMD != "" && contentMD != descMD {
+		renderedContent := m.renderMarkdown(contentMD, &links)
+		if renderedContent != "" && renderedContent != "\n" {
+			if out != "" {
+				out += "\n\n"
 			}
+			out += renderedContent
 		}
+	}
 
-		if out == "" {
-			out = "No content available."
-		}
-
-		return contentMsg(out)
+	if out == "" {
+		out = "No content available."
 	}
+
+	return contentMsg{text: out, links: links}
 }
 
 func (m Model) helpView() string {
-	return TitleStyle.Render("Keyboard Shortcuts") + "\n\n" +
-		lipgloss.JoinHorizontal(lipgloss.Top,
-			lipgloss.NewStyle().Width(32).Render(
-				lipgloss.JoinVertical(lipgloss.Left,
-					"General",
-					"  ?         Show/Hide Help",
-					"  q         Quit",
-					"  Tab / →  Next Pane",
-					"  S-Tab / ← Previous Pane",
-					"  Enter     Open Article in Browser",
-					"  t         Toggle Article View",
-					"  d         Toggle Entry Dates",
-					"  Esc       Cancel / Go Back",
-					"",
-					"Navigation",
-					"  ↑ / ↓     Move Cursor",
-					"  j / k     Move Cursor (vim)",
-				),
-			),
-			lipgloss.NewStyle().Width(32).Render(
-				lipgloss.JoinVertical(lipgloss.Left,
-					"Feeds Pane",
-					"  a         Add New Feed",
-					"  D         Delete Feed",
-					"  v         Toggle Feed Info",
-					"  r         Refresh All Feeds",
-					"  alt+↑ / alt+k  Move Feed Up",
-					"  alt+↓ / alt+j  Move Feed Down",
-					"  /         Filter Feeds",
-					"",
-					"Articles Pane",
-					"  r         Refresh Current Feed",
-					"  /         Filter Articles",
-				),
-			),
-			lipgloss.NewStyle().Width(32).Render(
-				lipgloss.JoinVertical(lipgloss.Left,
-					"Import/Export",
-					"  i         Import OPML",
-					"  e         Export OPML",
-					"",
-					"Article View",
-					"  ↑ / ↓     Scroll Up/Down",
-					"  Page Up   Scroll Page Up",
-					"  Page Down Scroll Page Down",
-					"  Home      Scroll to Top",
-					"  End       Scroll to Bottom",
-					"",
-					"Symbols",
-					"  Pink Text Unread Items",
-				),
-			),
-		) + "\n\n(press any key to return)"
-}
+	names, groups := m.keys.groups()
+	row := func(b key.Binding) string {
+		keys := b.Help().Key
+		if runewidth.StringWidth(keys) < 9 {
+			keys += strings.Repeat(" ", 9-runewidth.StringWidth(keys))
+		}
+		return "  " + keys + " " + b.Help().Desc
+	}
 
-func openBrowser(url string) {
-	var err error
-	switch runtime.GOOS {
-	case "linux":
-		err = exec.Command("xdg-open", url).Start()
-	case "windows":
-		err = exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
-	case "darwin":
-		err = exec.Command("open", url).Start()
-	}
-	_ = err
+	// Lay the groups out in three columns, filling them top to bottom
+	var columns [3][]string
+	total := 0
+	for _, g := range groups {
+		total += len(g) + 2
+	}
+	col := 0
+	for i, g := range groups {
+		if col < len(columns)-1 && len(columns[col]) > 0 && len(columns[col])+len(g) > total/len(columns)+1 {
+			col++
+		}
+		if len(columns[col]) > 0 {
+			columns[col] = append(columns[col], "")
+		}
+		columns[col] = append(columns[col], names[i])
+		for _, b := range g {
+			columns[col] = append(columns[col], row(b))
+		}
+	}
+	columns[col] = append(columns[col], "", "Symbols", "  Bold      Unread Items", "  Esc       Cancel / Go Back")
+
+	rendered := make([]string, len(columns))
+	for i, c := range columns {
+		rendered[i] = lipgloss.NewStyle().Width(36).Render(lipgloss.JoinVertical(lipgloss.Left, c...))
+	}
+	return TitleStyle.Render("Keyboard Shortcuts") + "\n\n" +
+		lipgloss.JoinHorizontal(lipgloss.Top, rendered...) +
+		"\n\nCounts like 5j repeat a movement, 5G jumps to line 5" +
+		"\n\n(press any key to return)"
 }
 
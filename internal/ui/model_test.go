@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newTestModel opens a database with two feeds of three unread entries
+// each, and shows the entries of the first feed
+func newTestModel(t *testing.T) Model {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+	if err := db.InitDB(filepath.Join(dir, "rss.db")); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC)
+	for f := range 2 {
+		id, err := db.AddFeed(fmt.Sprintf("http://feed%d.example/", f), fmt.Sprint("Feed ", f), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var entries []db.Entry
+		for i := range 3 {
+			entries = append(entries, db.Entry{
+				Title:       fmt.Sprintf("Entry %d.%d", f, i),
+				Link:        fmt.Sprintf("http://feed%d.example/%d", f, i),
+				Content:     fmt.Sprintf("<p>Text of entry %d.%d</p>", f, i),
+				PublishedAt: base.Add(time.Duration(i) * time.Hour),
+			})
+		}
+		if err := db.SaveEntries(id, entries); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m, err := NewModel(config.Default(), filepath.Join(dir, "config.toml"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ = update(m, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = update(m, m.loadFeeds())
+	feeds, err := db.GetFeeds(db.FeedSortManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.currentFeed = feeds[0]
+	m, _ = update(m, m.loadEntries(m.currentFeed)())
+	return m
+}
+
+func update(m Model, msg tea.Msg) (Model, tea.Cmd) {
+	tm, cmd := m.Update(msg)
+	return tm.(Model), cmd
+}
+
+// runCmd runs cmd, or the commands it batches, and updates m with their
+// messages. The commands these messages return are dropped.
+func runCmd(m Model, cmd tea.Cmd) Model {
+	if cmd == nil {
+		return m
+	}
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, c := range msg {
+			m = runCmd(m, c)
+		}
+	default:
+		m, _ = update(m, msg)
+	}
+	return m
+}
+
+// listedRead tells which entries of the list show as read
+func listedRead(m Model) []bool {
+	var read []bool
+	for _, it := range m.entriesList.Items() {
+		if e, ok := it.(entryItem); ok {
+			read = append(read, e.entry.Read)
+		}
+	}
+	return read
+}
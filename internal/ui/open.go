@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxStderr is how much of the error output of a handler is kept to tell
+// why it failed
+const maxStderr = 4096
+
+// handlerGrace is how long a program opening a link is watched for failing.
+// Programs still running after that are left alone.
+const handlerGrace = 2 * time.Second
+
+// openURL opens url with the first handler of the config that matches it,
+// else with the configured browser, $BROWSER or the OS default opener.
+// Failures are reported in the status bar.
+func (m Model) openURL(url string) tea.Cmd {
+	for _, h := range m.cfg.Handlers {
+		if h.Matches(url) {
+			return runHandler(commandArgs(h.Command, url), h.Terminal)
+		}
+	}
+	command := m.cfg.Browser.Command
+	if command == "" {
+		// $BROWSER may hold a colon-separated list, use the first entry
+		command, _, _ = strings.Cut(os.Getenv("BROWSER"), ":")
+	}
+	if command != "" {
+		return runHandler(commandArgs(command, url), false)
+	}
+
+	switch runtime.GOOS {
+	case "windows":
+		return runHandler([]string{"rundll32", "url.dll,FileProtocolHandler", url}, false)
+	case "darwin":
+		return runHandler([]string{"open", url}, false)
+	}
+	return runHandler([]string{"xdg-open", url}, false)
+}
+
+// commandArgs splits a handler command into arguments, quotes grouping
+// words, replacing {url} with the link or appending it when there is no
+// placeholder
+func commandArgs(command, url string) []string {
+	args := splitArgs(command)
+	hasPlaceholder := false
+	for i, a := range args {
+		if strings.Contains(a, "{url}") {
+			args[i] = strings.ReplaceAll(a, "{url}", url)
+			hasPlaceholder = true
+		}
+	}
+	if !hasPlaceholder {
+		args = append(args, url)
+	}
+	return args
+}
+
+// runHandler runs a program opening a link. Terminal programs like w3m get
+// the screen until they exit, others run in the background without it: an
+// error is reported if they fail soon after starting.
+func runHandler(args []string, terminal bool) tea.Cmd {
+	name := filepath.Base(args[0])
+	cmd := exec.Command(args[0], args[1:]...)
+	if terminal {
+		return execTerminal(cmd, func(err error) tea.Msg {
+			if err != nil {
+				return exportMsg(ErrorStyle.Render(name + ": " + err.Error()))
+			}
+			return nil
+		})
+	}
+	return func() tea.Msg {
+		stderr := &tailWriter{}
+		cmd.Stderr = stderr
+		if err := cmd.Start(); err != nil {
+			return exportMsg(ErrorStyle.Render(name + ": " + err.Error()))
+		}
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		var err error
+		select {
+		case err = <-done:
+		case <-time.After(handlerGrace):
+			// Still open, like a browser window
+			return nil
+		}
+		if err == nil {
+			return nil
+		}
+		// The last line a program writes usually tells what went wrong
+		var exitErr *exec.ExitError
+		if line := lastLine(string(stderr.buf)); errors.As(err, &exitErr) && line != "" {
+			return exportMsg(ErrorStyle.Render(name + ": " + line))
+		}
+		return exportMsg(ErrorStyle.Render(name + ": " + err.Error()))
+	}
+}
+
+// tailWriter keeps the end of what is written to it
+type tailWriter struct {
+	buf []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > maxStderr {
+		w.buf = w.buf[len(w.buf)-maxStderr:]
+	}
+	return len(p), nil
+}
+
+// lastLine returns the last line of s that isn't blank
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
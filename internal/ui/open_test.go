@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommandArgs(t *testing.T) {
+	const url = "https://example.com/a b"
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"firefox", []string{"firefox", url}},
+		{"firefox --new-tab {url}", []string{"firefox", "--new-tab", url}},
+		{`mpv --title "My Video" {url}`, []string{"mpv", "--title", "My Video", url}},
+		{"w3m --url={url}", []string{"w3m", "--url=" + url}},
+		{`"/opt/My Browser/browser"`, []string{"/opt/My Browser/browser", url}},
+	}
+	for _, tt := range tests {
+		if got := commandArgs(tt.command, url); !slices.Equal(got, tt.want) {
+			t.Errorf("commandArgs(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestLastLine(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"", ""},
+		{"error: bad\n", "error: bad"},
+		{"warning\n  error: bad  \n\n", "error: bad"},
+	}
+	for _, tt := range tests {
+		if got := lastLine(tt.in); got != tt.want {
+			t.Errorf("lastLine(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// terminal is the output of the program. Its writes don't interleave, so
+// escape sequences sent besides the renderer, like OSC 52, never land in
+// the middle of a frame.
+type terminal struct {
+	*os.File
+	mu sync.Mutex
+}
+
+func (t *terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(p)
+}
+
+var output = &terminal{File: os.Stdout}
+
+// Output is the writer the program draws to, passed with tea.WithOutput
+func Output() io.Writer {
+	return output
+}
+
+// execTerminal runs cmd with the screen until it exits. Its output goes
+// straight to the terminal: behind a writer that isn't a file, it would be
+// a pipe and programs like pagers wouldn't see a terminal.
+func execTerminal(cmd *exec.Cmd, fn tea.ExecCallback) tea.Cmd {
+	cmd.Stdout = output.File
+	return tea.ExecProcess(cmd, fn)
+}
@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// pipeMsg carries what a command the article was piped to printed
+type pipeMsg struct {
+	command string
+	output  string
+	err     error
+	stderr  string
+}
+
+// outputPopup shows the output of a command over the panes
+type outputPopup struct {
+	title string
+	view  viewport.Model
+}
+
+// pipedEntry is the JSON an article is piped as
+type pipedEntry struct {
+	Title       string     `json:"title"`
+	Link        string     `json:"link"`
+	Feed        string     `json:"feed"`
+	Published   *time.Time `json:"published,omitempty"`
+	Read        bool       `json:"read"`
+	Starred     bool       `json:"starred"`
+	Description string     `json:"description"`
+	Content     string     `json:"content"`
+	Markdown    string     `json:"markdown"`
+}
+
+// pipe sends the article shown to a command: a pipe of the config by
+// name, or a shell command optionally preceded by the format to send
+func (m Model) pipe(line string) (tea.Model, tea.Cmd) {
+	e, ok := m.shownEntry()
+	if !ok {
+		m.statusMsg = "No article selected"
+		return m, nil
+	}
+	format, command := config.PipeMarkdown, line
+	if first, rest, _ := strings.Cut(line, " "); slices.Contains(config.PipeFormats, first) {
+		format, command = first, strings.TrimSpace(rest)
+	}
+	for _, p := range m.cfg.Pipes {
+		if p.Name == line {
+			format, command = p.Format, p.Command
+			if format == "" {
+				format = config.PipeMarkdown
+			}
+		}
+	}
+	if command == "" {
+		return m.commandError("pipe: missing command")
+	}
+
+	// Pages opened from a link have no ID, nor a feed
+	if e.FeedTitle == "" && e.ID != 0 && !isVirtualFeed(m.currentFeed) {
+		e.FeedTitle = m.currentFeed.Title
+	}
+	input, err := pipeInput(e, format)
+	if err != nil {
+		return m.commandError("pipe: %v", err)
+	}
+	m.loading = true
+	m.statusMsg = "Piping to " + command
+	return m, func() tea.Msg {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/C", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		var stdout bytes.Buffer
+		stderr := &tailWriter{}
+		cmd.Stdin = strings.NewReader(input)
+		cmd.Stdout = &stdout
+		cmd.Stderr = stderr
+		err := cmd.Run()
+		return pipeMsg{command: command, output: stdout.String(), err: err, stderr: string(stderr.buf)}
+	}
+}
+
+// pipeInput is what of the entry is written to the command
+func pipeInput(e db.Entry, format string) (string, error) {
+	switch format {
+	case config.PipeURL:
+		return e.Link + "\n", nil
+	case config.PipeHTML:
+		if e.Content != "" {
+			return e.Content, nil
+		}
+		return e.Description, nil
+	}
+	md, err := entryMarkdown(e)
+	if err != nil || format == config.PipeMarkdown {
+		return md, err
+	}
+	out := pipedEntry{
+		Title:       e.Title,
+		Link:        e.Link,
+		Feed:        e.FeedTitle,
+		Read:        e.Read,
+		Starred:     e.Starred,
+		Description: e.Description,
+		Content:     e.Content,
+		Markdown:    md,
+	}
+	if !e.PublishedAt.IsZero() {
+		out.Published = &e.PublishedAt
+	}
+	b, err := json.MarshalIndent(out, "", "  ")
+	return string(b) + "\n", err
+}
+
+// showPipeOutput reports how a piped command went: errors and one line of
+// output in the status bar, longer output in a popup
+func (m Model) showPipeOutput(msg pipeMsg) Model {
+	m.loading = false
+	name, _, _ := strings.Cut(msg.command, " ")
+	if msg.err != nil {
+		var exitErr *exec.ExitError
+		if line := lastLine(msg.stderr); errors.As(msg.err, &exitErr) && line != "" {
+			m.statusMsg = ErrorStyle.Render(name + ": " + line)
+		} else {
+			m.statusMsg = ErrorStyle.Render(name + ": " + msg.err.Error())
+		}
+		return m
+	}
+	output := strings.TrimRight(msg.output, "\n")
+	switch {
+	case strings.TrimSpace(output) == "":
+		m.statusMsg = "Piped to " + name
+	case !strings.Contains(output, "\n"):
+		m.statusMsg = output
+	default:
+		m.statusMsg = ""
+		width := min(max(lipgloss.Width(output), lipgloss.Width(msg.command)+2), max(m.width-8, 20))
+		height := min(strings.Count(output, "\n")+1, max(m.height-8, 3))
+		m.output = outputPopup{title: msg.command, view: viewport.New(width, height)}
+		m.output.view.SetContent(output)
+		m.state = stateOutput
+	}
+	return m
+}
+
+func (m Model) updateOutput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "enter", "ctrl+c":
+		m.state = stateMain
+		return m, nil
+	case "j":
+		m.output.view.LineDown(1)
+	case "k":
+		m.output.view.LineUp(1)
+	case "g", "home":
+		m.output.view.GotoTop()
+	case "G", "end":
+		m.output.view.GotoBottom()
+	default:
+		var cmd tea.Cmd
+		m.output.view, cmd = m.output.view.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m Model) outputView() string {
+	title := PaneTitleStyle.Render(runewidth.Truncate(m.output.title, m.output.view.Width-2, "..."))
+	footer := StatusTextStyle.Render("esc close")
+	if m.output.view.TotalLineCount() > m.output.view.Height {
+		footer = StatusTextStyle.Render("j/k scroll · esc close")
+	}
+	box := ActivePaneStyle.Copy().Padding(0, 1).Render(
+		lipgloss.JoinVertical(lipgloss.Left, title, "", m.output.view.View(), "", footer))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
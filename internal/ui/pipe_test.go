@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/db"
+)
+
+func TestPipeInput(t *testing.T) {
+	e := db.Entry{
+		Title:       "Title",
+		Link:        "https://example.com/post",
+		FeedTitle:   "Blog",
+		PublishedAt: time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC),
+		Description: "<p>Summary</p>",
+		Content:     `<p>Some <a href="/other">text</a></p>`,
+	}
+	noContent := e
+	noContent.Content = ""
+	tests := []struct {
+		name   string
+		e      db.Entry
+		format string
+		want   string
+	}{
+		{"url", e, config.PipeURL, "https://example.com/post\n"},
+		{"html", e, config.PipeHTML, e.Content},
+		{"html of the description", noContent, config.PipeHTML, "<p>Summary</p>"},
+		{"markdown", e, config.PipeMarkdown, "Some [text](https://example.com/other)"},
+		{"markdown of the description", noContent, config.PipeMarkdown, "Summary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pipeInput(tt.e, tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	out, err := pipeInput(e, config.PipeJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got pipedEntry
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Title" || got.Feed != "Blog" || got.Published == nil || !got.Published.Equal(e.PublishedAt) ||
+		got.Markdown != "Some [text](https://example.com/other)" {
+		t.Errorf("unexpected JSON %s", out)
+	}
+	if out, _ := pipeInput(db.Entry{}, config.PipeJSON); strings.Contains(out, "published") {
+		t.Errorf("undated entry piped with a date: %s", out)
+	}
+}
+
+func TestPipe(t *testing.T) {
+	m := newTestModel(t)
+	m.cfg.Pipes = []config.Pipe{{Name: "link", Command: "cat", Format: config.PipeURL}}
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"link", "http://feed0.example/2"},
+		{"url tr a-z A-Z", "HTTP://FEED0.EXAMPLE/2"},
+		{"wc -w", "4"}, // the Markdown of the entry
+	}
+	for _, tt := range tests {
+		_, cmd := m.pipe(tt.line)
+		msg := cmd().(pipeMsg)
+		if msg.err != nil || strings.TrimSpace(msg.output) != tt.want {
+			t.Errorf("pipe %s printed %q (%v), want %q", tt.line, msg.output, msg.err, tt.want)
+		}
+	}
+}
+
+func TestShowPipeOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    pipeMsg
+		status string
+		popup  bool
+	}{
+		{"silent", pipeMsg{command: "archivebox add"}, "Piped to archivebox", false},
+		{"one line", pipeMsg{command: "wc -w", output: "42\n"}, "42", false},
+		{"several lines", pipeMsg{command: "cat", output: "a\nb\n"}, "", true},
+		{"failed", pipeMsg{command: "false", err: &exec.ExitError{}, stderr: "warning\nno network\n"}, "false: no network", false},
+		{"not started", pipeMsg{command: "nope", err: errors.New("not found")}, "nope: not found", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+			m = m.showPipeOutput(tt.msg)
+			if !strings.Contains(m.statusMsg, tt.status) || (tt.status == "") != (m.statusMsg == "") {
+				t.Errorf("status %q, want %q", m.statusMsg, tt.status)
+			}
+			if popup := m.state == stateOutput; popup != tt.popup {
+				t.Errorf("popup shown %v, want %v", popup, tt.popup)
+			}
+		})
+	}
+}
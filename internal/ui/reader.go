@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toggleReader shows the article alone, centered at the max_width of the
+// config, or goes back to the panes.
+func (m Model) toggleReader() (tea.Model, tea.Cmd) {
+	m.reader = !m.reader
+	if m.reader {
+		m.readerPane = m.activePane
+		m.activePane = paneContent
+	} else {
+		m.activePane = m.readerPane
+		if m.activePane == paneContent && !m.showArticleView {
+			m.activePane = paneEntries
+		}
+	}
+	cmd := m.relayout()
+	return m, cmd
+}
+
+// stepArticle shows the article n places below the selected one in the
+// articles list, or above it when n is negative.
+func (m Model) stepArticle(n int) (tea.Model, tea.Cmd) {
+	items := len(m.entriesList.VisibleItems())
+	if items == 0 {
+		return m, nil
+	}
+	before := m.entriesList.Index()
+	m.entriesList.Select(min(max(before+n, 0), items-1))
+	skipHeaders(&m.entriesList, n > 0)
+	if m.entriesList.Index() == before {
+		m.statusMsg = "No more articles"
+		return m, nil
+	}
+	cmd := tea.Batch(m.viewSelected(), m.loadMoreRiver())
+	return m, cmd
+}
+
+// readerProgress tells how far the article has been read
+func (m Model) readerProgress() string {
+	return fmt.Sprintf("%d%%", int(m.viewport.ScrollPercent()*100))
+}
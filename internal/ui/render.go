@@ -0,0 +1,178 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+)
+
+const (
+	// renderCacheSize is how many rendered articles are kept
+	renderCacheSize = 64
+	// prerenderCount is how many articles below the cursor are rendered
+	// ahead of time
+	prerenderCount = 3
+)
+
+var (
+	// linkedImageRe matches images wrapped in a link, [![alt](img-url)](link-url)
+	linkedImageRe = regexp.MustCompile(`\[!\[([^\]]*)\]\([^)]+\)\]\(([^)]+)\)`)
+	// linkRe matches links and images. The groups are 1: optional '\',
+	// 2: optional '!', 3: link text or alt, 4: url
+	linkRe = regexp.MustCompile(`(\\)?(!)?\[([^\]]*)\]\(\s*([^\s\)]+)(?:\s+["'][^"']*["'])?\s*\)`)
+)
+
+// renderKey identifies a rendering of an article
+type renderKey struct {
+	entryID int64
+	width   int
+	theme   string
+	// date is the date line above the article, which changes with the
+	// date settings and, for relative dates, with the time
+	date string
+}
+
+// renderedEntry is a cached rendering, with the article text it was made
+// from so that articles changed by a sync are rendered again
+type renderedEntry struct {
+	description string
+	content     string
+	msg         contentMsg
+}
+
+// renderCache keeps the last rendered articles, so that going back and
+// forth in a list doesn't convert and render them again. It is shared by
+// the copies of the Model and used by background commands.
+type renderCache struct {
+	mu      sync.Mutex
+	entries map[renderKey]renderedEntry
+	order   []renderKey // oldest first
+
+	// rendering serializes the use of the glamour renderer, which isn't
+	// safe for concurrent use
+	rendering sync.Mutex
+}
+
+func newRenderCache() *renderCache {
+	return &renderCache{entries: make(map[renderKey]renderedEntry)}
+}
+
+func (c *renderCache) get(k renderKey, e db.Entry) (contentMsg, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r, ok := c.entries[k]
+	if !ok || r.description != e.Description || r.content != e.Content {
+		return contentMsg{}, false
+	}
+	return r.msg, true
+}
+
+func (c *renderCache) put(k renderKey, e db.Entry, msg contentMsg) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[k]; !ok {
+		c.order = append(c.order, k)
+	}
+	c.entries[k] = renderedEntry{description: e.Description, content: e.Content, msg: msg}
+	for len(c.order) > renderCacheSize {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// clear drops everything, after a change of settings the key doesn't cover
+func (c *renderCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	c.order = nil
+}
+
+// wrapWidth is the width articles are rendered at for the content pane
+func (m Model) wrapWidth() int {
+	return max(m.contentWidth, 30) - 4
+}
+
+// rebuildRenderer makes a glamour renderer for the current width and theme.
+// A glamour style file that can't be loaded falls back to the colors of the
+// theme, and the previous renderer is kept if even that fails.
+func (m *Model) rebuildRenderer() {
+	r, err := glamour.NewTermRenderer(
+		m.theme.glamourOption(),
+		glamour.WithWordWrap(m.wrapWidth()),
+		glamour.WithEmoji(),
+	)
+	if err != nil && m.theme.glamourPath != "" {
+		m.statusMsg = ErrorStyle.Render(fmt.Sprintf("Glamour style %s: %v", m.theme.glamourPath, err))
+		r, err = glamour.NewTermRenderer(
+			glamour.WithStyles(m.theme.glamour),
+			glamour.WithWordWrap(m.wrapWidth()),
+			glamour.WithEmoji(),
+		)
+	}
+	if err != nil {
+		m.statusMsg = ErrorStyle.Render("Articles not rendered again: " + err.Error())
+		return
+	}
+	m.renderer = r
+	m.rendererWidth = m.wrapWidth()
+}
+
+// relayout resizes the panes after a layout change, and renders the
+// article again when its width changed
+func (m *Model) relayout() tea.Cmd {
+	m.recalcPaneDimensions()
+	if m.renderer != nil && m.rendererWidth == m.wrapWidth() {
+		return nil
+	}
+	m.rebuildRenderer()
+	return m.rerender()
+}
+
+// rerender renders the article shown again with the current renderer,
+// keeping the search and the scroll position
+func (m Model) rerender() tea.Cmd {
+	if m.pageURL != "" {
+		title, url, md, html := m.pageTitle, m.pageURL, m.pageMD, m.pageHTML
+		return func() tea.Msg {
+			msg := m.renderPage(title, url, md, html)
+			msg.rerender = true
+			return msg
+		}
+	}
+	i, ok := m.entriesList.SelectedItem().(entryItem)
+	if !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		msg := m.renderEntry(i.entry)
+		msg.rerender = true
+		return msg
+	}
+}
+
+// prerender renders the articles below the cursor in the background, so
+// that they show up at once when moving down the list
+func (m Model) prerender() tea.Cmd {
+	items := m.entriesList.VisibleItems()
+	var next []db.Entry
+	for i := m.entriesList.Index() + 1; i < len(items) && len(next) < prerenderCount; i++ {
+		if item, ok := items[i].(entryItem); ok {
+			next = append(next, item.entry)
+		}
+	}
+	if len(next) == 0 || m.renderer == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		for _, e := range next {
+			m.renderEntry(e)
+		}
+		return nil
+	}
+}
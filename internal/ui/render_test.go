@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/jeremiev/lazyrss/internal/db"
+)
+
+func TestRenderCache(t *testing.T) {
+	c := newRenderCache()
+	entry := func(id int64) db.Entry { return db.Entry{ID: id, Content: "<p>text</p>"} }
+	key := func(id int64) renderKey { return renderKey{entryID: id, width: 80, theme: "dark"} }
+	for id := range int64(renderCacheSize + 1) {
+		c.put(key(id), entry(id), contentMsg{text: "rendered"})
+	}
+	// Putting a key again doesn't count twice
+	c.put(key(renderCacheSize), entry(renderCacheSize), contentMsg{text: "rendered"})
+
+	if _, ok := c.get(key(0), entry(0)); ok {
+		t.Error("oldest article not evicted")
+	}
+	if msg, ok := c.get(key(1), entry(1)); !ok || msg.text != "rendered" {
+		t.Error("second article evicted")
+	}
+	if len(c.entries) != renderCacheSize || len(c.order) != renderCacheSize {
+		t.Errorf("%d entries and %d in order, want %d", len(c.entries), len(c.order), renderCacheSize)
+	}
+
+	changed := entry(1)
+	changed.Content = "<p>updated by a sync</p>"
+	if _, ok := c.get(key(1), changed); ok {
+		t.Error("changed article served from the cache")
+	}
+
+	c.clear()
+	if _, ok := c.get(key(1), entry(1)); ok || len(c.order) != 0 {
+		t.Error("clear left articles")
+	}
+}
+
+// The date line above articles changes with time and the time zone
+func TestRenderCacheDate(t *testing.T) {
+	c := newRenderCache()
+	e := db.Entry{ID: 1, Content: "<p>text</p>"}
+	key := renderKey{entryID: 1, width: 80, theme: "dark", date: "Wed, 13 Mar 2024 20:00 (now)"}
+	c.put(key, e, contentMsg{text: "rendered"})
+	if _, ok := c.get(key, e); !ok {
+		t.Error("article not served with the same date line")
+	}
+	key.date = "Wed, 13 Mar 2024 20:00 (5m ago)"
+	if _, ok := c.get(key, e); ok {
+		t.Error("article served with another date line")
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// riverFeedID identifies the virtual "All unread" feed pinned at the top of
+// the feeds pane, which lists unread entries of every feed together.
+const riverFeedID int64 = -1
+
+const (
+	riverPageSize = 200
+	// Fetch the next page once the cursor gets this close to the end
+	riverPrefetch = 20
+)
+
+// The river pages through entries by date, so it only has the date orders
+var riverSorts = []db.EntrySort{db.EntrySortNewest, db.EntrySortOldest}
+
+func riverFeed(unread int) db.Feed {
+	return db.Feed{ID: riverFeedID, Title: "All unread", UnreadCount: unread}
+}
+
+func isVirtualFeed(f db.Feed) bool {
+	return f.ID < 0
+}
+
+// loadRiverPage fetches the page of unread entries following after, or the
+// first page when after is nil.
+func (m Model) loadRiverPage(after *db.Entry) tea.Cmd {
+	prefs := m.feedPrefs
+	return func() tea.Msg {
+		if after == nil {
+			var err error
+			if prefs, err = db.GetFeedPrefs(riverFeedID); err != nil {
+				return errMsg(err)
+			}
+		}
+		if prefs.EntrySort != db.EntrySortOldest {
+			prefs.EntrySort = db.EntrySortNewest
+		}
+		entries, err := db.GetUnreadEntries(prefs.Query(riverFeedID, m.dates.now()), after, riverPageSize)
+		if err != nil {
+			return errMsg(err)
+		}
+		return entriesMsg{
+			feedID:     riverFeedID,
+			entries:    entries,
+			more:       len(entries) == riverPageSize,
+			appendPage: after != nil,
+			prefs:      prefs,
+		}
+	}
+}
+
+// loadMoreRiver fetches the next page when the cursor nears the end of what
+// has been loaded so far.
+func (m *Model) loadMoreRiver() tea.Cmd {
+	if m.currentFeed.ID != riverFeedID || !m.riverMore || m.riverLoading {
+		return nil
+	}
+	items := m.entriesList.Items()
+	if len(items) == 0 || m.entriesList.Index() < len(items)-riverPrefetch {
+		return nil
+	}
+	last, ok := items[len(items)-1].(entryItem)
+	if !ok {
+		return nil
+	}
+	m.riverLoading = true
+	return m.loadRiverPage(&last.entry)
+}
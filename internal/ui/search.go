@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// articleSearch is a search in the displayed article. Matches are found in
+// the text without its escape codes and highlighted by column, so the
+// styles of the rendered article are kept around them.
+type articleSearch struct {
+	query   string
+	matches []searchMatch
+	current int
+}
+
+// searchMatch spans the cells [start, end) of a line of the content pane
+type searchMatch struct {
+	line       int
+	start, end int
+}
+
+func newSearchInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "search article"
+	return ti
+}
+
+func (m *Model) openSearch() {
+	m.state = stateSearch
+	m.searchInput.SetValue("")
+	m.searchInput.Focus()
+	m.searchFrom = m.viewport.YOffset
+}
+
+// updateSearch searches as the query is typed, enter keeps the matches and
+// esc drops them.
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.state = stateMain
+		m.search = articleSearch{}
+		m.setContent()
+		m.viewport.SetYOffset(m.searchFrom)
+		return m, nil
+	case "enter":
+		m.state = stateMain
+		if m.search.query != "" && len(m.search.matches) == 0 {
+			m.statusMsg = ErrorStyle.Render("Not found: " + m.search.query)
+			m.search = articleSearch{}
+		}
+		return m, nil
+	case "backspace":
+		if m.searchInput.Value() == "" {
+			m.state = stateMain
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	if q := m.searchInput.Value(); q != m.search.query {
+		m.search = articleSearch{query: q}
+		m.setContent()
+		// Start from the first match below where the search began
+		for i, match := range m.search.matches {
+			if match.line >= m.searchFrom {
+				if i > 0 {
+					m.search.current = i
+					m.setContent()
+				}
+				break
+			}
+		}
+		m.showMatch()
+	}
+	return m, cmd
+}
+
+// nextMatch moves n matches forward, or back when n is negative. Without a
+// search it jumps to the next or previous unread entry instead, n and N
+// are bound to both.
+func (m Model) nextMatch(n int) (tea.Model, tea.Cmd) {
+	if len(m.search.matches) == 0 {
+		return m.jumpUnread(n > 0)
+	}
+	count := len(m.search.matches)
+	m.search.current = ((m.search.current+n)%count + count) % count
+	m.setContent()
+	m.showMatch()
+	return m, nil
+}
+
+// showMatch scrolls the current match into view, a third down the pane
+func (m *Model) showMatch() {
+	if len(m.search.matches) == 0 {
+		return
+	}
+	line := m.search.matches[m.search.current].line
+	if line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height {
+		m.viewport.SetYOffset(line - m.viewport.Height/3)
+	}
+}
+
+// clearSearch drops the search, reporting whether there was one
+func (m *Model) clearSearch() bool {
+	if m.search.query == "" {
+		return false
+	}
+	m.search = articleSearch{}
+	m.setContent()
+	return true
+}
+
+// searchStatus is shown in the status bar while a search is active
+func (m Model) searchStatus() string {
+	if len(m.search.matches) == 0 {
+		return "no match for " + m.search.query
+	}
+	return fmt.Sprintf("match %d/%d for %s", m.search.current+1, len(m.search.matches), m.search.query)
+}
+
+// highlightMatches finds the query in content and highlights every match,
+// the current one standing out.
+func (m *Model) highlightMatches(content string) string {
+	m.search.matches = nil
+	if m.search.query == "" {
+		return content
+	}
+	query := lowerRunes(m.search.query)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		plain := lowerRunes(ansi.Strip(line))
+		var spans []searchMatch
+		for j := 0; j+len(query) <= len(plain); {
+			if !runesEqual(plain[j:j+len(query)], query) {
+				j++
+				continue
+			}
+			start := ansi.StringWidth(string(plain[:j]))
+			spans = append(spans, searchMatch{line: i, start: start, end: start + ansi.StringWidth(string(query))})
+			j += len(query)
+		}
+		// Highlight from the end, the columns of earlier matches stay put
+		for k := len(spans) - 1; k >= 0; k-- {
+			style := SearchMatchStyle
+			if len(m.search.matches)+k == m.search.current {
+				style = CurrentMatchStyle
+			}
+			s := spans[k]
+			line = ansi.Truncate(line, s.start, "") +
+				style.Render(ansi.Strip(ansi.Cut(line, s.start, s.end))) +
+				ansi.TruncateLeft(line, s.end, "")
+		}
+		lines[i] = line
+		m.search.matches = append(m.search.matches, spans...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lowerRunes lowercases s rune by rune, so that positions in the result
+// match the ones in s
+func lowerRunes(s string) []rune {
+	r := []rune(s)
+	for i := range r {
+		r[i] = unicode.ToLower(r[i])
+	}
+	return r
+}
+
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
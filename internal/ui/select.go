@@ -0,0 +1,381 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// selectionMsg reports a bulk action that has been applied
+type selectionMsg struct {
+	status string
+	// reload the feeds afterwards, for actions that change them
+	reloadFeeds bool
+	// readIDs are entries marked read, or unread without read, updated in
+	// the list in place
+	readIDs []int64
+	read    bool
+	// undo reverts the action, for actions whose changes are only known
+	// once they ran
+	undo *undoStep
+}
+
+// selectedFeedIDs returns the feeds a bulk action applies to: the selected
+// ones, or the one under the cursor when none is.
+func (m Model) selectedFeedIDs() []int64 {
+	var ids []int64
+	for _, it := range m.feedsList.Items() {
+		if fi, ok := it.(feedItem); ok && m.selectedFeeds[fi.feed.ID] {
+			ids = append(ids, fi.feed.ID)
+		}
+	}
+	if len(ids) == 0 {
+		if fi, ok := m.feedsList.SelectedItem().(feedItem); ok && !isVirtualFeed(fi.feed) {
+			ids = append(ids, fi.feed.ID)
+		}
+	}
+	return ids
+}
+
+// selectedEntries returns the entries a bulk action applies to, in list
+// order: the selected ones, or the one under the cursor when none is.
+func (m Model) selectedEntries() []db.Entry {
+	var entries []db.Entry
+	for _, it := range m.entriesList.Items() {
+		if ei, ok := it.(entryItem); ok && m.selectedEntryIDs[ei.entry.ID] {
+			entries = append(entries, ei.entry)
+		}
+	}
+	if len(entries) == 0 {
+		if ei, ok := m.entriesList.SelectedItem().(entryItem); ok {
+			entries = append(entries, ei.entry)
+		}
+	}
+	return entries
+}
+
+// toggleSelect flips the selection of the item under the cursor and moves
+// down, so several items can be picked in a row.
+func (m Model) toggleSelect() (tea.Model, tea.Cmd) {
+	switch m.activePane {
+	case paneFeeds:
+		fi, ok := m.feedsList.SelectedItem().(feedItem)
+		if !ok || isVirtualFeed(fi.feed) {
+			return m, nil
+		}
+		setSelected(m.selectedFeeds, fi.feed.ID, !m.selectedFeeds[fi.feed.ID])
+	case paneEntries:
+		ei, ok := m.entriesList.SelectedItem().(entryItem)
+		if !ok {
+			return m, nil
+		}
+		setSelected(m.selectedEntryIDs, ei.entry.ID, !m.selectedEntryIDs[ei.entry.ID])
+	default:
+		return m, nil
+	}
+	return m, m.navigate("down", 1)
+}
+
+// selectRange marks the start of a range on the first use, and selects
+// everything between it and the cursor on the second.
+func (m Model) selectRange() (tea.Model, tea.Cmd) {
+	if m.activePane == paneContent {
+		return m, nil
+	}
+	l := m.activeList()
+	if m.rangeAnchor < 0 || m.rangePane != m.activePane {
+		m.rangeAnchor, m.rangePane = l.Index(), m.activePane
+		m.statusMsg = "Range started, move to its other end and select again"
+		return m, nil
+	}
+	from, to := min(m.rangeAnchor, l.Index()), max(m.rangeAnchor, l.Index())
+	m.rangeAnchor = -1
+	m.statusMsg = ""
+	items := l.VisibleItems()
+	for i := from; i <= to && i < len(items); i++ {
+		m.selectItem(items[i], true)
+	}
+	return m, nil
+}
+
+// selectAll selects every item the filter lets through, or clears them when
+// they all are selected already.
+func (m Model) selectAll() (tea.Model, tea.Cmd) {
+	if m.activePane == paneContent {
+		return m, nil
+	}
+	items := m.activeList().VisibleItems()
+	all := true
+	for _, it := range items {
+		if !m.isItemSelected(it) {
+			all = false
+			break
+		}
+	}
+	for _, it := range items {
+		m.selectItem(it, !all)
+	}
+	return m, nil
+}
+
+// clearSelection drops the selection of both lists, reporting whether there
+// was one
+func (m *Model) clearSelection() bool {
+	if len(m.selectedFeeds) == 0 && len(m.selectedEntryIDs) == 0 && m.rangeAnchor < 0 {
+		return false
+	}
+	// Cleared in place, the items share these maps
+	clear(m.selectedFeeds)
+	clear(m.selectedEntryIDs)
+	m.rangeAnchor = -1
+	return true
+}
+
+func (m *Model) activeList() *list.Model {
+	if m.activePane == paneEntries {
+		return &m.entriesList
+	}
+	return &m.feedsList
+}
+
+func (m *Model) selectItem(it list.Item, selected bool) {
+	switch it := it.(type) {
+	case feedItem:
+		if !isVirtualFeed(it.feed) {
+			setSelected(m.selectedFeeds, it.feed.ID, selected)
+		}
+	case entryItem:
+		setSelected(m.selectedEntryIDs, it.entry.ID, selected)
+	}
+}
+
+func (m Model) isItemSelected(it list.Item) bool {
+	switch it := it.(type) {
+	case feedItem:
+		return isVirtualFeed(it.feed) || m.selectedFeeds[it.feed.ID]
+	case entryItem:
+		return m.selectedEntryIDs[it.entry.ID]
+	case dayHeader:
+		return true
+	}
+	return false
+}
+
+func setSelected(selection map[int64]bool, id int64, selected bool) {
+	if selected {
+		selection[id] = true
+	} else {
+		delete(selection, id)
+	}
+}
+
+// updateEntries changes the listed entries matching ids in place, after a
+// bulk action did the same in the database
+func (m *Model) updateEntries(ids []int64, change func(*db.Entry)) tea.Cmd {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	items := m.entriesList.Items()
+	for i, it := range items {
+		if ei, ok := it.(entryItem); ok && set[ei.entry.ID] {
+			change(&ei.entry)
+			items[i] = ei
+		}
+	}
+	return m.entriesList.SetItems(items)
+}
+
+func entryIDs(entries []db.Entry) []int64 {
+	ids := make([]int64, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
+	}
+	return ids
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+// togglePause pauses the targeted feeds, or resumes them when they all are
+// paused already
+func (m Model) togglePause() (tea.Model, tea.Cmd) {
+	ids := m.selectedFeedIDs()
+	if len(ids) == 0 {
+		return m, nil
+	}
+	targets := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		targets[id] = true
+	}
+	paused := false
+	for _, it := range m.feedsList.Items() {
+		if fi, ok := it.(feedItem); ok && targets[fi.feed.ID] && !fi.feed.Paused {
+			paused = true
+		}
+	}
+	m.clearSelection()
+	status := "Resumed "
+	if paused {
+		status = "Paused "
+	}
+	return m, func() tea.Msg {
+		if err := db.SetFeedsPaused(ids, paused); err != nil {
+			return errMsg(err)
+		}
+		return selectionMsg{status: status + plural(len(ids), "feed"), reloadFeeds: true}
+	}
+}
+
+// moveToFolder puts the targeted feeds in a folder, or out of any folder
+// when the name is empty
+func (m Model) moveToFolder(folder string) (tea.Model, tea.Cmd) {
+	ids := m.selectedFeedIDs()
+	if len(ids) == 0 {
+		return m.commandError("folder: no feed selected")
+	}
+	folder = strings.TrimSpace(strings.Trim(folder, "/"))
+	m.clearSelection()
+	status := "Moved " + plural(len(ids), "feed") + " to " + folder
+	if folder == "" {
+		status = "Moved " + plural(len(ids), "feed") + " out of their folder"
+	}
+	return m, func() tea.Msg {
+		if err := db.SetFeedsFolder(ids, folder); err != nil {
+			return errMsg(err)
+		}
+		return selectionMsg{status: status, reloadFeeds: true}
+	}
+}
+
+// toggleRead marks the targeted entries read, or unread when they all are
+// read already
+func (m Model) toggleRead() (tea.Model, tea.Cmd) {
+	entries := m.selectedEntries()
+	if len(entries) == 0 {
+		return m, nil
+	}
+	read := false
+	for _, e := range entries {
+		if !e.Read {
+			read = true
+		}
+	}
+	ids := entryIDs(entries)
+	var changed []int64
+	for _, e := range entries {
+		if e.Read != read {
+			changed = append(changed, e.ID)
+		}
+	}
+	cmd := m.updateEntries(ids, func(e *db.Entry) { e.Read = read })
+	m.clearSelection()
+	state := "unread"
+	if read {
+		state = "read"
+	}
+	status := "Marked " + plural(len(ids), "article") + " " + state
+	if len(changed) > 0 {
+		m.pushUndo(undoStep{
+			description: "marking " + plural(len(changed), "article") + " " + state,
+			undo:        setRead(changed, !read, "Restored the read state of "+plural(len(changed), "article")),
+		})
+	}
+	return m, tea.Batch(cmd, setRead(ids, read, status))
+}
+
+// setRead marks entries read or unread in the database, then in the list
+func setRead(ids []int64, read bool, status string) tea.Cmd {
+	return func() tea.Msg {
+		if err := db.SetEntriesRead(ids, read); err != nil {
+			return errMsg(err)
+		}
+		return selectionMsg{status: status, reloadFeeds: true, readIDs: ids, read: read}
+	}
+}
+
+// toggleStar stars the targeted entries, or unstars them when they all are
+// starred already
+func (m Model) toggleStar() (tea.Model, tea.Cmd) {
+	entries := m.selectedEntries()
+	if len(entries) == 0 {
+		return m, nil
+	}
+	starred := false
+	for _, e := range entries {
+		if !e.Starred {
+			starred = true
+		}
+	}
+	ids := entryIDs(entries)
+	cmd := m.updateEntries(ids, func(e *db.Entry) { e.Starred = starred })
+	m.clearSelection()
+	status := "Unstarred " + plural(len(ids), "article")
+	if starred {
+		status = "Starred " + plural(len(ids), "article")
+	}
+	return m, tea.Batch(cmd, func() tea.Msg {
+		if err := db.SetEntriesStarred(ids, starred); err != nil {
+			return errMsg(err)
+		}
+		return selectionMsg{status: status}
+	})
+}
+
+func (m Model) exportEntries() tea.Msg {
+	home, _ := os.UserHomeDir()
+	return m.exportEntriesTo(filepath.Join(home, "Downloads", "articles_export.md"))()
+}
+
+// exportEntriesTo writes the targeted entries as a Markdown list of links
+func (m Model) exportEntriesTo(exportPath string) tea.Cmd {
+	entries := m.selectedEntries()
+	feedTitle := m.currentFeed.Title
+	loc := m.dates.loc
+	return func() tea.Msg {
+		if len(entries) == 0 {
+			return exportMsg("No articles to export")
+		}
+		var b strings.Builder
+		for _, e := range entries {
+			source := e.FeedTitle
+			if source == "" {
+				source = feedTitle
+			}
+			fmt.Fprintf(&b, "- [%s](%s)", strings.ReplaceAll(e.Title, "]", "\\]"), e.Link)
+			if source != "" {
+				fmt.Fprintf(&b, " · %s", source)
+			}
+			if !e.PublishedAt.IsZero() {
+				fmt.Fprintf(&b, " · %s", e.PublishedAt.In(loc).Format(db.DateLayout))
+			}
+			b.WriteString("\n")
+		}
+		if err := os.WriteFile(exportPath, []byte(b.String()), 0644); err != nil {
+			return errMsg(err)
+		}
+		return exportMsg(fmt.Sprintf("Exported %s to %s", plural(len(entries), "article"), exportPath))
+	}
+}
+
+func completeFolders(m Model, arg string) []string {
+	seen := make(map[string]bool)
+	var folders []string
+	for _, it := range m.feedsList.Items() {
+		if fi, ok := it.(feedItem); ok && fi.feed.Folder != "" && !seen[fi.feed.Folder] {
+			seen[fi.feed.Folder] = true
+			folders = append(folders, fi.feed.Folder)
+		}
+	}
+	return filterPrefix(folders, arg)
+}
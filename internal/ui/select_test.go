@@ -0,0 +1,111 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func titles(m Model) []string {
+	var titles []string
+	for _, e := range m.selectedEntries() {
+		titles = append(titles, e.Title)
+	}
+	return titles
+}
+
+func TestSelectEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions func(m Model) Model
+		want    []string
+	}{
+		{"cursor without a selection", func(m Model) Model { return m }, []string{"Entry 0.2"}},
+		{"toggled one by one", func(m Model) Model {
+			m = step(m.toggleSelect()) // selects and moves down
+			m.entriesList.Select(2)
+			return step(m.toggleSelect())
+		}, []string{"Entry 0.2", "Entry 0.0"}},
+		{"toggled twice", func(m Model) Model {
+			m = step(m.toggleSelect())
+			m.entriesList.Select(0)
+			return step(m.toggleSelect())
+		}, []string{"Entry 0.1"}}, // the cursor moved down
+		{"range", func(m Model) Model {
+			m.entriesList.Select(2)
+			m = step(m.selectRange())
+			m.entriesList.Select(1)
+			return step(m.selectRange())
+		}, []string{"Entry 0.1", "Entry 0.0"}},
+		{"all", func(m Model) Model { return step(m.selectAll()) }, []string{"Entry 0.2", "Entry 0.1", "Entry 0.0"}},
+		{"all then none", func(m Model) Model {
+			m = step(m.selectAll())
+			return step(m.selectAll())
+		}, []string{"Entry 0.2"}},
+		{"cleared", func(m Model) Model {
+			m = step(m.selectAll())
+			m.clearSelection()
+			return m
+		}, []string{"Entry 0.2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+			m.activePane = paneEntries
+			m = tt.actions(m)
+			if got := titles(m); !slices.Equal(got, tt.want) {
+				t.Errorf("selected %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// The river and the recently read list can't be selected
+func TestSelectFeeds(t *testing.T) {
+	m := newTestModel(t)
+	m.activePane = paneFeeds
+	m = step(m.selectAll())
+	var want []int64
+	for _, it := range m.feedsList.Items() {
+		if fi, ok := it.(feedItem); ok && !isVirtualFeed(fi.feed) {
+			want = append(want, fi.feed.ID)
+		}
+	}
+	if got := m.selectedFeedIDs(); len(want) != 2 || !slices.Equal(got, want) {
+		t.Errorf("selected feeds %v, want %v", got, want)
+	}
+}
+
+func TestExportEntries(t *testing.T) {
+	m := newTestModel(t)
+	m.activePane = paneEntries
+	m = step(m.selectAll())
+	// 12:00 UTC is already the next day there
+	m.dates.loc = time.FixedZone("UTC+13", 13*3600)
+
+	path := filepath.Join(t.TempDir(), "articles.md")
+	msg := m.exportEntriesTo(path)()
+	if got, want := string(msg.(exportMsg)), "Exported 3 articles to "+path; got != want {
+		t.Errorf("status %q, want %q", got, want)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "- [Entry 0.2](http://feed0.example/2) · Feed 0 · 2024-03-14\n" +
+		"- [Entry 0.1](http://feed0.example/1) · Feed 0 · 2024-03-14\n" +
+		"- [Entry 0.0](http://feed0.example/0) · Feed 0 · 2024-03-14\n"
+	if string(b) != want {
+		t.Errorf("exported\n%s\nwant\n%s", b, want)
+	}
+}
+
+// step keeps the model of an action, the list updates it returns don't
+// matter here
+func step(m tea.Model, _ tea.Cmd) Model {
+	return m.(Model)
+}
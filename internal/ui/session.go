@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sessionMsg carries what was selected when lazyrss was last quit
+type sessionMsg db.Session
+
+func (m Model) loadSession() tea.Msg {
+	s, err := db.GetSession()
+	if err != nil {
+		return errMsg(err)
+	}
+	return sessionMsg(s)
+}
+
+// restoreSession selects the feed, entry and pane of the last session.
+// Feeds and entries that are gone leave the first ones selected.
+func (m Model) restoreSession(s db.Session) (tea.Model, tea.Cmd) {
+	for idx, it := range m.feedsList.Items() {
+		if fi, ok := it.(feedItem); ok && fi.feed.ID == s.FeedID {
+			m.feedsList.Select(idx)
+			break
+		}
+	}
+	i, ok := m.feedsList.SelectedItem().(feedItem)
+	if !ok {
+		return m, nil
+	}
+	m.currentFeed = i.feed
+	if pane := state(s.Pane); pane == paneEntries || pane == paneContent {
+		m.activePane = pane
+	}
+	return m, selectEntry(m.loadEntries(i.feed), s.EntryID)
+}
+
+// saveSession remembers the selection and how far the article shown was
+// read, for the next start
+func (m Model) saveSession() tea.Cmd {
+	s := db.Session{FeedID: m.currentFeed.ID, Pane: int(m.activePane)}
+	if i, ok := m.entriesList.SelectedItem().(entryItem); ok {
+		s.EntryID = i.entry.ID
+	}
+	savePosition := m.saveReadingPosition()
+	return func() tea.Msg {
+		if savePosition != nil {
+			if msg := savePosition(); msg != nil {
+				return msg
+			}
+		}
+		if err := db.SetSession(s); err != nil {
+			return errMsg(err)
+		}
+		return nil
+	}
+}
+
+// saveReadingPosition remembers how far the article shown was scrolled, so
+// that it opens there next time
+func (m Model) saveReadingPosition() tea.Cmd {
+	pos := m.viewport.ScrollPercent()
+	if m.viewport.TotalLineCount() <= m.viewport.Height {
+		pos = 0
+	}
+	id := m.shownEntryID
+	if id == 0 || pos == m.shownPosition {
+		return nil
+	}
+	return func() tea.Msg {
+		if err := db.SetReadingPosition(id, pos); err != nil {
+			return errMsg(err)
+		}
+		return nil
+	}
+}
+
+// scrollToPercent scrolls the article to a position saved with
+// ScrollPercent, which doesn't depend on its length
+func (m *Model) scrollToPercent(pos float64) {
+	m.viewport.SetYOffset(int(pos * float64(max(m.viewport.TotalLineCount()-m.viewport.Height, 0))))
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// restart opens a new model on the database of the tests, the way
+// lazyrss starts, and restores the last session
+func restart(t *testing.T) Model {
+	t.Helper()
+	m, err := NewModel(config.Default(), filepath.Join(t.TempDir(), "config.toml"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ = update(m, tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = update(m, m.loadFeeds())
+	m, cmd := update(m, m.loadSession())
+	return runCmd(m, cmd)
+}
+
+func TestRestoreSession(t *testing.T) {
+	tests := []struct {
+		name  string
+		pane  state
+		entry int // index in the list of the second feed
+	}{
+		{"article", paneContent, 2},
+		{"articles list", paneEntries, 1},
+		{"feeds pane", paneFeeds, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t)
+			feeds, _ := db.GetFeeds(db.FeedSortManual)
+			m.currentFeed = feeds[1]
+			m, _ = update(m, m.loadEntries(m.currentFeed)())
+			m.entriesList.Select(tt.entry)
+			m.activePane = tt.pane
+			want := m.entriesList.SelectedItem().(entryItem).entry
+			runCmd(m, m.saveSession())
+
+			m = restart(t)
+			if m.currentFeed.ID != feeds[1].ID {
+				t.Errorf("feed %q restored, want %q", m.currentFeed.Title, feeds[1].Title)
+			}
+			if m.activePane != tt.pane {
+				t.Errorf("pane %d restored, want %d", m.activePane, tt.pane)
+			}
+			if e, ok := m.entriesList.SelectedItem().(entryItem); !ok || e.entry.ID != want.ID {
+				t.Errorf("entry %q restored, want %q", e.entry.Title, want.Title)
+			}
+		})
+	}
+}
+
+// A feed deleted since leaves the first one selected
+func TestRestoreSessionDeletedFeed(t *testing.T) {
+	newTestModel(t)
+	if err := db.SetSession(db.Session{FeedID: 999, EntryID: 999, Pane: int(paneContent)}); err != nil {
+		t.Fatal(err)
+	}
+	m := restart(t)
+	first := m.feedsList.Items()[0].(feedItem).feed
+	if m.feedsList.Index() != 0 || m.currentFeed.ID != first.ID {
+		t.Errorf("feed %q selected, want the first one, %q", m.currentFeed.Title, first.Title)
+	}
+}
+
+func TestReadingPosition(t *testing.T) {
+	m := newTestModel(t)
+	e := m.entriesList.SelectedItem().(entryItem).entry
+	long := strings.Repeat("line\n", 200)
+	m, _ = update(m, contentMsg{text: long, entryID: e.ID, feedID: e.FeedID})
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("new article opened at line %d", m.viewport.YOffset)
+	}
+	m.scrollToPercent(0.5)
+	runCmd(m, m.saveReadingPosition())
+	if pos, _ := db.GetReadingPosition(e.ID); pos < 0.49 || pos > 0.51 {
+		t.Fatalf("position %v saved, want 0.5", pos)
+	}
+
+	// Opened again, the article is scrolled where it was left
+	m, _ = update(m, contentMsg{text: "other", entryID: e.ID + 1, feedID: e.FeedID})
+	m, _ = update(m, contentMsg{text: long, entryID: e.ID, feedID: e.FeedID, position: 0.5})
+	if pos := m.viewport.ScrollPercent(); pos < 0.49 || pos > 0.51 {
+		t.Errorf("opened at %v, want 0.5", pos)
+	}
+	// Nothing is saved for an article that didn't move
+	m, _ = update(m, contentMsg{text: long, entryID: e.ID + 1, feedID: e.FeedID})
+	if cmd := m.saveReadingPosition(); cmd != nil {
+		t.Error("unchanged position saved")
+	}
+}
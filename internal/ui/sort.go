@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sortLabel renders the sort shown next to a pane title
+func sortLabel(sort string) string {
+	if sort == "" {
+		return ""
+	}
+	return HelpStyle.Render("by " + sort)
+}
+
+// nextSort returns the sort following cur in sorts, wrapping around
+func nextSort[T comparable](sorts []T, cur T) T {
+	for i, s := range sorts {
+		if s == cur {
+			return sorts[(i+1)%len(sorts)]
+		}
+	}
+	return sorts[0]
+}
+
+// sortNames lists the sorts available in the active pane
+func (m Model) sortNames() []string {
+	var names []string
+	if m.activePane == paneFeeds {
+		for _, s := range db.FeedSorts {
+			names = append(names, string(s))
+		}
+		return names
+	}
+	for _, s := range m.entrySorts() {
+		names = append(names, string(s))
+	}
+	return names
+}
+
+func (m Model) entrySorts() []db.EntrySort {
+	if m.currentFeed.ID == riverFeedID {
+		return riverSorts
+	}
+	return db.EntrySorts
+}
+
+// cycleSort switches the active pane to its next sort: the feeds pane
+// sorts the feeds, the other panes the entries of the current feed.
+func (m Model) cycleSort() (tea.Model, tea.Cmd) {
+	if m.activePane == paneFeeds {
+		return m.setSort(string(nextSort(db.FeedSorts, m.feedSort)))
+	}
+	return m.setSort(string(nextSort(m.entrySorts(), m.feedPrefs.EntrySort)))
+}
+
+// setSort applies a sort by name to the active pane and saves it, keeping
+// the cursor on the same feed or entry.
+func (m Model) setSort(name string) (tea.Model, tea.Cmd) {
+	if m.activePane == paneFeeds {
+		sort := db.FeedSort(name)
+		if !slices.Contains(db.FeedSorts, sort) {
+			return m.commandError("Unknown feed sort: %s", name)
+		}
+		m.feedSort = sort
+		m.statusMsg = "Feeds sorted by " + name
+		return m, func() tea.Msg {
+			if err := db.SetFeedSort(sort); err != nil {
+				return errMsg(err)
+			}
+			return m.loadFeeds()
+		}
+	}
+
+	if m.currentFeed.ID == 0 {
+		return m, nil
+	}
+	if m.currentFeed.ID == recentFeedID {
+		m.statusMsg = recentOrderNote
+		return m, nil
+	}
+	sort := db.EntrySort(name)
+	if !slices.Contains(m.entrySorts(), sort) {
+		return m.commandError("Unknown sort for %s: %s", m.currentFeed.Title, name)
+	}
+	m.feedPrefs.EntrySort = sort
+	m.statusMsg = "Articles sorted by " + name
+	return m, m.updateFeedPrefs(func(p *db.FeedPrefs) { p.EntrySort = sort })
+}
@@ -0,0 +1,18 @@
+package ui
+
+import "testing"
+
+func TestNextSort(t *testing.T) {
+	sorts := []string{"newest", "oldest", "title"}
+	tests := []struct{ cur, want string }{
+		{"newest", "oldest"},
+		{"title", "newest"},
+		{"unknown", "newest"},
+		{"", "newest"},
+	}
+	for _, tt := range tests {
+		if got := nextSort(sorts, tt.cur); got != tt.want {
+			t.Errorf("nextSort(%q) = %q, want %q", tt.cur, got, tt.want)
+		}
+	}
+}
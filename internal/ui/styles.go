@@ -7,58 +7,148 @@ import (
 var (
 	DocStyle = lipgloss.NewStyle().Padding(0, 2)
 
+	TitleStyle lipgloss.Style
+
+	StatusStyle lipgloss.Style
+
+	StatusPillStyle lipgloss.Style
+
+	StatusTextStyle lipgloss.Style
+
+	StatusHelpStyle lipgloss.Style
+
+	ErrorStyle lipgloss.Style
+
+	HelpStyle lipgloss.Style
+
+	BorderStyle lipgloss.Style
+
+	UnreadItemStyle = lipgloss.NewStyle().
+			Bold(true)
+
+	ReadItemStyle lipgloss.Style
+
+	SelectedMarkStyle lipgloss.Style
+
+	DescriptionReadingStyle lipgloss.Style
+
+	ActivePaneStyle lipgloss.Style
+
+	InactivePaneStyle lipgloss.Style
+
+	DateStyle lipgloss.Style
+
+	LinkStyle lipgloss.Style
+
+	LinkHintStyle lipgloss.Style
+
+	SearchMatchStyle lipgloss.Style
+
+	CurrentMatchStyle lipgloss.Style
+
+	MetaStyle lipgloss.Style
+
+	LabelStyle lipgloss.Style
+
+	InfoStyle lipgloss.Style
+
+	PaneTitleStyle lipgloss.Style
+)
+
+func init() {
+	applyTheme(darkTheme)
+}
+
+// applyTheme rebuilds every style from the theme's colors
+func applyTheme(t Theme) {
 	TitleStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(t.Accent).
+		Bold(true).
+		Padding(0, 1)
 
 	StatusStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252"))
+		Foreground(t.Text)
 
 	StatusPillStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205")).
-			Padding(0, 1).
-			Bold(true)
+		Foreground(t.Accent).
+		Padding(0, 1).
+		Bold(true)
 
 	StatusTextStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252")).
-			Padding(0, 1)
+		Foreground(t.Text).
+		Padding(0, 1)
 
 	StatusHelpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("252")).
-			Padding(0, 1)
+		Foreground(t.Text).
+		Padding(0, 1)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("196")).
-			Bold(true)
+		Foreground(t.Error).
+		Bold(true)
 
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
+		Foreground(t.Muted)
 
 	BorderStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), true).
-			BorderForeground(lipgloss.Color("240"))
+		Border(lipgloss.NormalBorder(), true).
+		BorderForeground(t.Border)
 
-	UnreadItemStyle = lipgloss.NewStyle().
-			Bold(true)
+	ReadItemStyle = lipgloss.NewStyle().
+		Foreground(t.Muted)
+
+	SelectedMarkStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Bold(true)
 
 	DescriptionReadingStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("252")).
-				Italic(true).
-				MarginLeft(2).
-				BorderLeft(true).
-				BorderStyle(lipgloss.ThickBorder()).
-				BorderForeground(lipgloss.Color("205"))
+		Foreground(t.Text).
+		Italic(true).
+		MarginLeft(2).
+		BorderLeft(true).
+		BorderStyle(lipgloss.ThickBorder()).
+		BorderForeground(t.Accent)
 
 	ActivePaneStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), true).
-			BorderForeground(lipgloss.Color("205"))
+		Border(lipgloss.NormalBorder(), true).
+		BorderForeground(t.Accent)
 
 	InactivePaneStyle = lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder(), true).
-			BorderForeground(lipgloss.Color("240"))
+		Border(lipgloss.NormalBorder(), true).
+		BorderForeground(t.Border)
 
 	DateStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-)
+		Foreground(t.Muted)
+
+	LinkStyle = lipgloss.NewStyle().
+		Foreground(t.Link).
+		Underline(true)
+
+	LinkHintStyle = lipgloss.NewStyle().
+		Foreground(t.Accent).
+		Bold(true)
+
+	SearchMatchStyle = lipgloss.NewStyle().
+		Reverse(true)
+
+	CurrentMatchStyle = lipgloss.NewStyle().
+		Background(t.Accent).
+		Foreground(t.TitleFg).
+		Bold(true)
+
+	MetaStyle = lipgloss.NewStyle().
+		Foreground(t.Muted).
+		PaddingLeft(2)
+
+	LabelStyle = lipgloss.NewStyle().
+		Foreground(t.Muted).
+		Bold(true)
+
+	InfoStyle = lipgloss.NewStyle().
+		Padding(1, 2).
+		Foreground(t.Text)
 
+	PaneTitleStyle = lipgloss.NewStyle().
+		Background(t.TitleBg).
+		Foreground(t.TitleFg).
+		Padding(0, 1)
+}
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme is the color scheme shared by the lipgloss styles and the glamour
+// renderer.
+type Theme struct {
+	Name    string
+	Accent  lipgloss.Color
+	Text    lipgloss.Color
+	Muted   lipgloss.Color
+	Border  lipgloss.Color
+	Error   lipgloss.Color
+	Link    lipgloss.Color
+	TitleFg lipgloss.Color
+	TitleBg lipgloss.Color
+
+	// Article style, glamourPath takes precedence when set
+	glamour     ansi.StyleConfig
+	glamourPath string
+}
+
+var darkTheme = Theme{
+	Name:    "dark",
+	Accent:  "205",
+	Text:    "252",
+	Muted:   "241",
+	Border:  "240",
+	Error:   "196",
+	Link:    "12",
+	TitleFg: "230",
+	TitleBg: "62",
+	glamour: styles.DarkStyleConfig,
+}
+
+var lightTheme = Theme{
+	Name:    "light",
+	Accent:  "162",
+	Text:    "236",
+	Muted:   "244",
+	Border:  "250",
+	Error:   "160",
+	Link:    "26",
+	TitleFg: "230",
+	TitleBg: "62",
+	glamour: styles.LightStyleConfig,
+}
+
+var highContrastTheme = Theme{
+	Name:    "high-contrast",
+	Accent:  "11",
+	Text:    "15",
+	Muted:   "250",
+	Border:  "15",
+	Error:   "9",
+	Link:    "14",
+	TitleFg: "0",
+	TitleBg: "11",
+	glamour: highContrastGlamour(),
+}
+
+// highContrastGlamour brightens the dark glamour style: plain white text and
+// saturated colors for everything that stands out.
+func highContrastGlamour() ansi.StyleConfig {
+	s := styles.DarkStyleConfig
+	white, yellow, cyan, black := "15", "11", "14", "0"
+	s.Document.Color = &white
+	s.Heading.Color = &cyan
+	s.H1.Color = &black
+	s.H1.BackgroundColor = &yellow
+	s.H6.Color = &cyan
+	s.Link.Color = &cyan
+	s.LinkText.Color = &yellow
+	s.Image.Color = &yellow
+	s.Code.Color = &yellow
+	s.Code.BackgroundColor = &black
+	s.CodeBlock.Color = &white
+	return s
+}
+
+func builtinTheme(name string) (Theme, bool) {
+	switch name {
+	case "dark":
+		return darkTheme, true
+	case "light":
+		return lightTheme, true
+	case "high-contrast":
+		return highContrastTheme, true
+	}
+	return Theme{}, false
+}
+
+// resolveTheme turns a theme name from the config into a Theme. "auto" and
+// user themes without a base follow the terminal background. Bare glamour
+// styles keep the default colors and only change the article style. Other
+// names are an error.
+func resolveTheme(name string, cfg *config.Config, darkBackground bool) (Theme, error) {
+	base := darkTheme
+	if !darkBackground {
+		base = lightTheme
+	}
+	if name == "auto" {
+		return base, nil
+	}
+	if t, ok := builtinTheme(name); ok {
+		return t, nil
+	}
+	if tc, ok := cfg.Themes[name]; ok {
+		if b, ok := builtinTheme(tc.Base); ok {
+			base = b
+		}
+		base.Name = name
+		for _, c := range []struct {
+			dst *lipgloss.Color
+			src string
+		}{
+			{&base.Accent, tc.Accent}, {&base.Text, tc.Text}, {&base.Muted, tc.Muted},
+			{&base.Border, tc.Border}, {&base.Error, tc.Error}, {&base.Link, tc.Link},
+			{&base.TitleFg, tc.TitleFg}, {&base.TitleBg, tc.TitleBg},
+		} {
+			if c.src != "" {
+				*c.dst = lipgloss.Color(c.src)
+			}
+		}
+		base.glamourPath = tc.Glamour
+		return base, nil
+	}
+	path := config.ExpandHome(name)
+	if !config.IsGlamourStyle(path) {
+		return Theme{}, fmt.Errorf("unknown theme %q", name)
+	}
+	base.Name = name
+	base.glamourPath = path
+	return base, nil
+}
+
+// themeNames lists the themes that can be cycled through at runtime
+func themeNames(cfg *config.Config) []string {
+	names := append([]string{}, config.BuiltinThemes...)
+	var user []string
+	for name := range cfg.Themes {
+		user = append(user, name)
+	}
+	sort.Strings(user)
+	return append(names, user...)
+}
+
+func (t Theme) glamourOption() glamour.TermRendererOption {
+	if t.glamourPath != "" {
+		return glamour.WithStylePath(t.glamourPath)
+	}
+	return glamour.WithStyles(t.glamour)
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeremiev/lazyrss/internal/config"
+)
+
+func TestResolveTheme(t *testing.T) {
+	style := filepath.Join(t.TempDir(), "style.json")
+	if err := os.WriteFile(style, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	cfg.Themes = map[string]config.Theme{"mine": {Base: "light", Accent: "#ff0000"}}
+	tests := []struct {
+		name, want string // want is the Theme name, empty for an error
+	}{
+		{"auto", "dark"},
+		{"light", "light"},
+		{"mine", "mine"},
+		{"dracula", "dracula"},
+		{style, style},
+		{"drak", ""},
+		{filepath.Join(t.TempDir(), "missing.json"), ""},
+	}
+	for _, tt := range tests {
+		got, err := resolveTheme(tt.name, cfg, true)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("resolveTheme(%q) accepted", tt.name)
+		case tt.want != "" && err != nil:
+			t.Errorf("resolveTheme(%q): %v", tt.name, err)
+		case got.Name != tt.want:
+			t.Errorf("resolveTheme(%q) = %q, want %q", tt.name, got.Name, tt.want)
+		}
+	}
+	if mine, _ := resolveTheme("mine", cfg, true); string(mine.Accent) != "#ff0000" || mine.Text != lightTheme.Text {
+		t.Errorf("user theme colors %v %v, want its accent on the light base", mine.Accent, mine.Text)
+	}
+}
+
+func TestRebuildRendererKeepsWorking(t *testing.T) {
+	style := filepath.Join(t.TempDir(), "style.json")
+	if err := os.WriteFile(style, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := Model{theme: darkTheme}
+	m.rebuildRenderer()
+	if m.renderer == nil {
+		t.Fatal("no renderer for the dark theme")
+	}
+	// A style file broken after the config was checked
+	m.theme.glamourPath = style
+	m.rebuildRenderer()
+	if m.renderer == nil || m.statusMsg == "" {
+		t.Errorf("renderer %v, status %q: want the theme colors and an error", m.renderer, m.statusMsg)
+	}
+}
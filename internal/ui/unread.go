@@ -0,0 +1,157 @@
+package ui
+
+import (
+	"github.com/jeremiev/lazyrss/internal/db"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// unreadMsg reports the feed and entry holding the next unread entry, or
+// that there is none left in that direction.
+type unreadMsg struct {
+	feed    db.Feed
+	entryID int64
+	found   bool
+}
+
+// jumpUnread moves to the next (or previous) unread entry, first in the
+// entries pane and then across feeds in the order of the feeds pane.
+func (m Model) jumpUnread(forward bool) (tea.Model, tea.Cmd) {
+	step := 1
+	if !forward {
+		step = -1
+	}
+	items := m.entriesList.VisibleItems()
+	for i := m.entriesList.Index() + step; i >= 0 && i < len(items); i += step {
+		if e, ok := items[i].(entryItem); ok && !e.entry.Read {
+			m.entriesList.Select(i)
+			return m, tea.Batch(m.viewSelected(), m.loadMoreRiver())
+		}
+	}
+
+	// The river already holds the unread entries of every feed
+	if m.currentFeed.ID == riverFeedID {
+		if forward && m.riverMore {
+			m.entriesList.Select(len(items) - 1)
+			return m, m.loadMoreRiver()
+		}
+		m.statusMsg = "No more unread entries"
+		return m, nil
+	}
+
+	var feeds []db.Feed
+	start := -1
+	for _, it := range m.feedsList.Items() {
+		fi, ok := it.(feedItem)
+		if !ok || isVirtualFeed(fi.feed) {
+			continue
+		}
+		if fi.feed.ID == m.currentFeed.ID {
+			start = len(feeds)
+		}
+		feeds = append(feeds, fi.feed)
+	}
+	if start < 0 && !forward {
+		start = len(feeds)
+	}
+	return m, func() tea.Msg {
+		unread, err := db.GetUnreadFeedIDs()
+		if err != nil {
+			return errMsg(err)
+		}
+		for i := start + step; i >= 0 && i < len(feeds); i += step {
+			if !unread[feeds[i].ID] {
+				continue
+			}
+			prefs, err := db.GetFeedPrefs(feeds[i].ID)
+			if err != nil {
+				return errMsg(err)
+			}
+			entries, err := db.GetEntries(prefs.Query(feeds[i].ID, m.dates.now()))
+			if err != nil {
+				return errMsg(err)
+			}
+			for j := range entries {
+				e := entries[j]
+				if !forward {
+					e = entries[len(entries)-1-j]
+				}
+				if !e.Read {
+					return unreadMsg{feed: feeds[i], entryID: e.ID, found: true}
+				}
+			}
+		}
+		return unreadMsg{}
+	}
+}
+
+// markReadNext marks the selected entry read and moves on to the next
+// unread one.
+func (m Model) markReadNext() (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	if i, ok := m.entriesList.SelectedItem().(entryItem); ok && !i.entry.Read {
+		cmd = m.setSelectedRead()
+		entryID := i.entry.ID
+		cmd = tea.Batch(cmd, func() tea.Msg {
+			if err := db.MarkAsRead(entryID); err != nil {
+				return errMsg(err)
+			}
+			return nil
+		})
+	}
+	next, nextCmd := m.jumpUnread(true)
+	return next, tea.Batch(cmd, nextCmd)
+}
+
+// showUnread selects the feed an unread entry was found in and loads its
+// entries with that entry selected.
+func (m Model) showUnread(msg unreadMsg) (tea.Model, tea.Cmd) {
+	if !msg.found {
+		m.statusMsg = "No more unread entries"
+		return m, nil
+	}
+	if m.feedsList.FilterState() != list.Unfiltered {
+		m.feedsList.ResetFilter()
+	}
+	for idx, it := range m.feedsList.Items() {
+		if fi, ok := it.(feedItem); ok && fi.feed.ID == msg.feed.ID {
+			m.feedsList.Select(idx)
+			msg.feed = fi.feed
+			break
+		}
+	}
+	m.currentFeed = msg.feed
+	return m, selectEntry(m.loadEntries(msg.feed), msg.entryID)
+}
+
+// selectEntry makes the entries loaded by cmd open with entryID selected
+func selectEntry(cmd tea.Cmd, entryID int64) tea.Cmd {
+	return func() tea.Msg {
+		msg := cmd()
+		if eMsg, ok := msg.(entriesMsg); ok {
+			eMsg.selectID = entryID
+			return eMsg
+		}
+		return msg
+	}
+}
+
+// viewSelected opens the selected entry. It is flagged read in the list
+// right away, viewEntry takes care of the database.
+func (m *Model) viewSelected() tea.Cmd {
+	i, ok := m.entriesList.SelectedItem().(entryItem)
+	if !ok {
+		return nil
+	}
+	return tea.Batch(m.setSelectedRead(), m.viewEntry(i.entry), m.prerender())
+}
+
+func (m *Model) setSelectedRead() tea.Cmd {
+	i, ok := m.entriesList.SelectedItem().(entryItem)
+	if !ok || i.entry.Read {
+		return nil
+	}
+	i.entry.Read = true
+	return m.entriesList.SetItem(m.entriesList.GlobalIndex(), i)
+}
@@ -0,0 +1,39 @@
+package xdg
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const appName = "lazyrss"
+
+// DataDir returns the directory lazyrss keeps its databases in, honoring
+// $XDG_DATA_HOME and falling back to ~/.local/share.
+func DataDir() (string, error) {
+	return appDir("XDG_DATA_HOME", ".local", "share")
+}
+
+// ConfigDir returns the directory lazyrss reads its configuration from,
+// honoring $XDG_CONFIG_HOME and falling back to ~/.config.
+func ConfigDir() (string, error) {
+	return appDir("XDG_CONFIG_HOME", ".config")
+}
+
+// CacheDir returns the directory for disposable data, honoring
+// $XDG_CACHE_HOME and falling back to ~/.cache.
+func CacheDir() (string, error) {
+	return appDir("XDG_CACHE_HOME", ".cache")
+}
+
+func appDir(env string, fallback ...string) (string, error) {
+	// The spec says relative paths must be ignored
+	if base := os.Getenv(env); base != "" && filepath.IsAbs(base) {
+		return filepath.Join(base, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	parts := append([]string{home}, fallback...)
+	return filepath.Join(append(parts, appName)...), nil
+}
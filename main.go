@@ -1,8 +1,11 @@
 package main
 
 import (
+	"github.com/jeremiev/lazyrss/internal/config"
 	"github.com/jeremiev/lazyrss/internal/db"
+	"github.com/jeremiev/lazyrss/internal/rss"
 	"github.com/jeremiev/lazyrss/internal/ui"
+	"flag"
 	"fmt"
 	"os"
 
@@ -10,17 +13,55 @@ import (
 )
 
 func main() {
-	if err := db.InitDB(); err != nil {
+	dbPath := flag.String("db", "", "path to the database file (overrides --profile)")
+	profile := flag.String("profile", "", "name of a separate set of subscriptions to use")
+	configPath := flag.String("config", "", "path to the config file")
+	flag.Parse()
+
+	if *configPath == "" {
+		var err error
+		*configPath, err = config.Path()
+		if err != nil {
+			fmt.Printf("Error resolving config path: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Printf("Error in config file %v\n", err)
+		os.Exit(1)
+	}
+	if err := rss.Configure(cfg.HTTP.Timeout, cfg.HTTP.UserAgent, cfg.HTTP.Proxy); err != nil {
+		fmt.Printf("Error configuring HTTP client: %v\n", err)
+		os.Exit(1)
+	}
+
+	path := *dbPath
+	if path != "" {
+		// The profile isn't the one open, don't show its name
+		*profile = ""
+	} else {
+		path, err = db.Path(*profile)
+		if err != nil {
+			fmt.Printf("Error resolving database path: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := db.InitDB(path); err != nil {
 		fmt.Printf("Error initializing database: %v\n", err)
 		os.Exit(1)
 	}
 
-	m := ui.NewModel()
-	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
+	m, err := ui.NewModel(cfg, *configPath, *profile)
+	if err != nil {
+		fmt.Printf("Error in config file %v\n", err)
+		os.Exit(1)
+	}
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithOutput(ui.Output()))
 
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
 }
-